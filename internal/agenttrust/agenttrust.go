@@ -0,0 +1,113 @@
+// Package agenttrust knows how to mark a working directory as trusted in
+// each coding agent's own on-disk config format (Copilot, Cursor, Claude
+// Code, Gemini, Windsurf, Codex, Auggie). It replaces the Copilot-specific
+// trusted-folders logic that used to live inline in internal/config: that
+// package now resolves which agent a role/rig combination uses and asks
+// this package for the matching TrustStore, instead of hardcoding one
+// agent's config shape.
+package agenttrust
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config/permguard"
+)
+
+// TrustStore knows how to read and update one agent's on-disk "trusted
+// folders" list.
+type TrustStore interface {
+	// Path returns the on-disk config file this store reads and writes.
+	Path() string
+	// Load returns the folders currently marked trusted.
+	Load() ([]string, error)
+	// Ensure marks folder as trusted, creating or updating the store's
+	// config file as needed. role records which Gas Town role requested
+	// the trust grant (e.g. "witness", "polecat"); stores that track
+	// provenance (see objectArrayStore) persist it alongside the folder,
+	// stores that merely append to a third-party tool's own trust file
+	// (see flatArrayStore) ignore it. It reports whether the file was
+	// changed.
+	Ensure(folder, role string) (updated bool, err error)
+}
+
+// Factory builds the TrustStore for an agent, rooted at configDir (an empty
+// configDir selects the agent's default per-user config location).
+type Factory func(configDir string) (TrustStore, error)
+
+var (
+	registry = map[string]Factory{}
+	order    []string
+)
+
+// Register adds (or replaces) the factory for agent. Built-in stores
+// register themselves via init(); downstream packages can Register stores
+// for agents gastown doesn't ship without forking this package.
+func Register(agent string, factory Factory) {
+	name := strings.ToLower(agent)
+	if _, exists := registry[name]; !exists {
+		order = append(order, name)
+	}
+	registry[name] = factory
+}
+
+// NewStore builds the TrustStore for agent, rooted at configDir. It returns
+// an error if no store is registered for agent.
+func NewStore(agent, configDir string) (TrustStore, error) {
+	factory, ok := registry[strings.ToLower(agent)]
+	if !ok {
+		return nil, fmt.Errorf("agenttrust: no trust store registered for agent %q", agent)
+	}
+	return factory(configDir)
+}
+
+// Registered reports whether agent has a registered store, without
+// constructing one.
+func Registered(agent string) bool {
+	_, ok := registry[strings.ToLower(agent)]
+	return ok
+}
+
+// RegisteredAgents returns every registered agent name, in registration
+// order.
+func RegisteredAgents() []string {
+	out := make([]string, len(order))
+	copy(out, order)
+	return out
+}
+
+// VerifyPermissions checks that store's config file (and its parent
+// directory) still have the restrictive permissions Ensure writes with --
+// a trusted_folders entry is a privilege grant, so a file someone's since
+// chmod'd world-readable is worth catching. Load already runs the file half
+// of this check in warn-only mode on every read; VerifyPermissions, which
+// also checks the directory, exists for a caller
+// (e.g. a `gt doctor` check) that wants strict to turn a widened file into
+// a hard error instead of a logged warning.
+func VerifyPermissions(store TrustStore, strict bool) error {
+	path := store.Path()
+	if err := permguard.CheckDir(filepath.Dir(path)); err != nil {
+		if !strict {
+			return permguard.Warn(err)
+		}
+		return err
+	}
+	if err := permguard.CheckFile(path); err != nil {
+		if !strict {
+			return permguard.Warn(err)
+		}
+		return err
+	}
+	return nil
+}
+
+func init() {
+	Register("copilot", newCopilotStore)
+	Register("cursor", newCursorStore)
+	Register("claude", newClaudeStore)
+	Register("gemini", newGeminiStore)
+	Register("windsurf", newWindsurfStore)
+	Register("codex", newCodexStore)
+	Register("auggie", newAuggieStore)
+}
@@ -0,0 +1,245 @@
+package agenttrust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config/permguard"
+	"github.com/steveyegge/gastown/internal/schema"
+)
+
+func TestNewStore_UnknownAgent(t *testing.T) {
+	if _, err := NewStore("not-a-real-agent", t.TempDir()); err == nil {
+		t.Fatal("expected an error for an unregistered agent")
+	}
+}
+
+func TestRegisteredAgents_IncludesBuiltins(t *testing.T) {
+	want := []string{"copilot", "cursor", "claude", "gemini", "windsurf", "codex", "auggie"}
+	got := map[string]bool{}
+	for _, agent := range RegisteredAgents() {
+		got[agent] = true
+	}
+	for _, agent := range want {
+		if !got[agent] {
+			t.Errorf("RegisteredAgents() missing %q: %v", agent, RegisteredAgents())
+		}
+		if !Registered(agent) {
+			t.Errorf("Registered(%q) = false, want true", agent)
+		}
+	}
+}
+
+func TestObjectArrayStore_EnsureAndLoad(t *testing.T) {
+	configDir := t.TempDir()
+	store, err := NewStore("claude", configDir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	updated, err := store.Ensure("/work/dir", "witness")
+	if err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if !updated {
+		t.Fatal("expected first Ensure to report updated=true")
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 1 || entries[0] != "/work/dir" {
+		t.Fatalf("Load() = %v, want [/work/dir]", entries)
+	}
+
+	data, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v, _ := cfg["version"].(float64); int(v) != schema.CurrentTrustConfigVersion {
+		t.Errorf("expected version %d, got %v", schema.CurrentTrustConfigVersion, cfg["version"])
+	}
+	permissions, _ := cfg["permissions"].(map[string]any)
+	if permissions == nil || permissions["additionalDirectories"] == nil {
+		t.Fatalf("expected permissions.additionalDirectories in %v", cfg)
+	}
+	dirs, _ := permissions["additionalDirectories"].([]any)
+	entry, _ := dirs[0].(map[string]any)
+	if entry["path"] != "/work/dir" || entry["added_by_role"] != "witness" {
+		t.Fatalf("expected a versioned trust entry for witness, got %v", entry)
+	}
+
+	// A second Ensure for the same folder is a no-op.
+	updated, err = store.Ensure("/work/dir", "witness")
+	if err != nil {
+		t.Fatalf("second Ensure: %v", err)
+	}
+	if updated {
+		t.Fatal("expected second Ensure to report updated=false")
+	}
+}
+
+func TestFlatArrayStore_EnsureAndLoad(t *testing.T) {
+	configDir := t.TempDir()
+	store, err := NewStore("cursor", configDir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if filepath.Base(store.Path()) != "trusted_folders" {
+		t.Fatalf("Path() = %q, want a trusted_folders file", store.Path())
+	}
+
+	if _, err := store.Ensure("/work/dir", "witness"); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 1 || entries[0] != "/work/dir" {
+		t.Fatalf("Load() = %v, want [/work/dir]", entries)
+	}
+
+	updated, err := store.Ensure("/work/dir", "witness")
+	if err != nil {
+		t.Fatalf("second Ensure: %v", err)
+	}
+	if updated {
+		t.Fatal("expected second Ensure to report updated=false")
+	}
+}
+
+func TestLoad_MissingFileReportsNoEntries(t *testing.T) {
+	for _, agent := range RegisteredAgents() {
+		store, err := NewStore(agent, t.TempDir())
+		if err != nil {
+			t.Fatalf("NewStore(%q): %v", agent, err)
+		}
+		entries, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load(%q) on a missing file: %v", agent, err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("Load(%q) on a missing file = %v, want none", agent, entries)
+		}
+	}
+}
+
+func TestEnsure_WritesRestrictivePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	// Use an unset configDir (resolved under $HOME) rather than t.TempDir()
+	// directly: t.TempDir() itself is created 0755 by the testing package,
+	// which would mask whether our own MkdirAll(..., 0700) ran at all, since
+	// MkdirAll never chmods a directory that already exists.
+	t.Setenv("HOME", t.TempDir())
+	store, err := NewStore("cursor", "")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if _, err := store.Ensure("/work/dir", "witness"); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatalf("stat %s: %v", store.Path(), err)
+	}
+	if perm := info.Mode().Perm(); perm != permguard.MaxFileMode {
+		t.Errorf("%s mode = %04o, want %04o", store.Path(), perm, permguard.MaxFileMode)
+	}
+
+	dirInfo, err := os.Stat(filepath.Dir(store.Path()))
+	if err != nil {
+		t.Fatalf("stat config dir: %v", err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != permguard.MaxDirMode {
+		t.Errorf("config dir mode = %04o, want %04o", perm, permguard.MaxDirMode)
+	}
+}
+
+func TestVerifyPermissions_StrictRefusesWidenedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	configDir := t.TempDir()
+	store, err := NewStore("cursor", configDir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	if _, err := store.Ensure("/work/dir", "witness"); err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if err := os.Chmod(store.Path(), 0644); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+
+	if err := VerifyPermissions(store, false); err != nil {
+		t.Errorf("non-strict VerifyPermissions should warn, not error, got %v", err)
+	}
+	if err := VerifyPermissions(store, true); err == nil {
+		t.Error("expected strict VerifyPermissions to refuse a widened file")
+	}
+}
+
+// TestObjectArrayStore_ConcurrentEnsureEachAddOneEntryExactlyOnce exercises
+// the scenario that matters for ~/.copilot/config.json in particular: a
+// rig's polecats, witness, and refinery all booting at once and calling
+// Ensure against the same shared file. Every Ensure call serializes through
+// atomicjson.Update's advisory flock, re-reading the file after acquiring
+// the lock, so no concurrent writer ever clobbers another's entry.
+func TestObjectArrayStore_ConcurrentEnsureEachAddOneEntryExactlyOnce(t *testing.T) {
+	configDir := t.TempDir()
+	store, err := NewStore("copilot", configDir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	const n = 32
+	var wg sync.WaitGroup
+	errCh := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := store.Ensure(fmt.Sprintf("/work/dir-%d", i), "polecat")
+			errCh <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			t.Fatalf("Ensure: %v", err)
+		}
+	}
+
+	entries, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	seen := map[string]int{}
+	for _, e := range entries {
+		seen[e]++
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d unique entries, got %d: %v", n, len(seen), entries)
+	}
+	for entry, count := range seen {
+		if count != 1 {
+			t.Errorf("entry %q appeared %d times, want 1", entry, count)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package agenttrust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config/atomicjson"
+	"github.com/steveyegge/gastown/internal/config/permguard"
+	"github.com/steveyegge/gastown/internal/pathutil"
+	"github.com/steveyegge/gastown/internal/schema"
+)
+
+// resolvePath resolves the config file a store reads and writes: configDir
+// (if set) wins outright, otherwise envVar (if non-empty and set) roots the
+// path, falling back to $HOME.
+func resolvePath(configDir, envVar, subdir, filename string) (string, error) {
+	if configDir != "" {
+		return filepath.Join(configDir, filename), nil
+	}
+	if envVar != "" {
+		if v := os.Getenv(envVar); v != "" {
+			return filepath.Join(v, subdir, filename), nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+	return filepath.Join(home, subdir, filename), nil
+}
+
+// samePath reports whether a and b refer to the same canonical path,
+// resolving symlinks and applying the host OS's case-folding. config can't
+// be imported directly here (config depends on agenttrust for trust-folder
+// providers, so that would be a cycle), so the canonicalization logic
+// itself lives in pathutil, a leaf package both sides import.
+func samePath(a, b string) bool {
+	return pathutil.SamePath(a, b)
+}
+
+// objectArrayStore trusts folders by maintaining a versioned array of
+// {path, added_at, added_by_role} entries at keyPath inside a JSON object
+// file -- Copilot and Gemini's shared trusted_folders field, or Claude's
+// nested permissions.additionalDirectories. Every read and write is
+// validated against schemaName (see internal/schema) so a hand-edited file
+// that doesn't match the expected shape is rejected rather than silently
+// misread or clobbered.
+type objectArrayStore struct {
+	path       string
+	keyPath    []string
+	schemaName schema.Name
+}
+
+func (s *objectArrayStore) Path() string { return s.path }
+
+func (s *objectArrayStore) Load() ([]string, error) {
+	_ = permguard.Warn(permguard.CheckFile(s.path))
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+
+	entries, err := s.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+	}
+	return paths, nil
+}
+
+// decode parses data and returns the current trust entries, migrating a
+// legacy (pre-version) flat string array forward in memory. It validates
+// data against s.schemaName only when the file already carries the current
+// version -- a legacy file hasn't been migrated yet, so it wouldn't match
+// the current schema, and that's expected rather than an error.
+func (s *objectArrayStore) decode(data []byte) ([]schema.TrustFolderEntry, error) {
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+
+	if v, _ := cfg["version"].(float64); int(v) == schema.CurrentTrustConfigVersion {
+		if err := schema.Validate(s.schemaName, data); err != nil {
+			return nil, fmt.Errorf("%s does not match the %s schema: %w", s.path, s.schemaName, err)
+		}
+	}
+
+	container := cfg
+	for _, key := range s.keyPath[:len(s.keyPath)-1] {
+		next, ok := container[key].(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		container = next
+	}
+
+	raw := container[s.keyPath[len(s.keyPath)-1]]
+	entries, err := schema.DecodeTrustFolders(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+func (s *objectArrayStore) Ensure(folder, role string) (bool, error) {
+	var changed bool
+	err := atomicjson.Update(s.path, func(cfg map[string]any) (bool, error) {
+		container := cfg
+		for _, key := range s.keyPath[:len(s.keyPath)-1] {
+			next, ok := container[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				container[key] = next
+			}
+			container = next
+		}
+		lastKey := s.keyPath[len(s.keyPath)-1]
+
+		entries, err := schema.DecodeTrustFolders(container[lastKey])
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", s.path, err)
+		}
+
+		for _, existing := range entries {
+			if samePath(existing.Path, folder) {
+				return false, nil
+			}
+		}
+		entries = append(entries, schema.TrustFolderEntry{
+			Path:        folder,
+			AddedAt:     time.Now().UTC().Format(time.RFC3339),
+			AddedByRole: role,
+		})
+		container[lastKey] = schema.EncodeTrustFolders(entries)
+		cfg["version"] = schema.CurrentTrustConfigVersion
+
+		candidate, err := json.Marshal(cfg)
+		if err != nil {
+			return false, fmt.Errorf("encoding %s: %w", s.path, err)
+		}
+		if err := schema.Validate(s.schemaName, candidate); err != nil {
+			return false, fmt.Errorf("refusing to write %s: %w", s.path, err)
+		}
+
+		changed = true
+		return true, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// flatArrayStore trusts folders by maintaining a string array at the root
+// of a JSON file -- Cursor, Windsurf, Codex, and Auggie's dedicated
+// trusted_folders files.
+type flatArrayStore struct {
+	path string
+}
+
+func (s *flatArrayStore) Path() string { return s.path }
+
+func (s *flatArrayStore) Load() ([]string, error) {
+	_ = permguard.Warn(permguard.CheckFile(s.path))
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, nil
+	}
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// Ensure ignores role: flatArrayStore writes a third-party tool's own
+// trusted_folders file verbatim as a flat path list, so there's nowhere to
+// record provenance without inventing a shape that tool doesn't expect.
+func (s *flatArrayStore) Ensure(folder, role string) (bool, error) {
+	var changed bool
+	err := atomicjson.UpdateStringArray(s.path, func(entries []string) ([]string, bool, error) {
+		for _, existing := range entries {
+			if samePath(existing, folder) {
+				return entries, false, nil
+			}
+		}
+		changed = true
+		return append(entries, folder), true, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func newCopilotStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "XDG_CONFIG_HOME", ".copilot", "config.json")
+	if err != nil {
+		return nil, err
+	}
+	return &objectArrayStore{path: path, keyPath: []string{"trusted_folders"}, schemaName: schema.CopilotTrustConfig}, nil
+}
+
+// newGeminiStore shares Copilot's config.json layout, as the two CLIs
+// happen to use the same shape for trusted_folders.
+func newGeminiStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".gemini", "config.json")
+	if err != nil {
+		return nil, err
+	}
+	return &objectArrayStore{path: path, keyPath: []string{"trusted_folders"}, schemaName: schema.CopilotTrustConfig}, nil
+}
+
+func newClaudeStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".claude", "settings.json")
+	if err != nil {
+		return nil, err
+	}
+	return &objectArrayStore{path: path, keyPath: []string{"permissions", "additionalDirectories"}, schemaName: schema.ClaudeSettings}, nil
+}
+
+func newCursorStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".cursor", "trusted_folders")
+	if err != nil {
+		return nil, err
+	}
+	return &flatArrayStore{path: path}, nil
+}
+
+func newWindsurfStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".windsurf", "trusted_folders")
+	if err != nil {
+		return nil, err
+	}
+	return &flatArrayStore{path: path}, nil
+}
+
+func newCodexStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".codex", "trusted_folders")
+	if err != nil {
+		return nil, err
+	}
+	return &flatArrayStore{path: path}, nil
+}
+
+func newAuggieStore(configDir string) (TrustStore, error) {
+	path, err := resolvePath(configDir, "", ".augment", "trusted_folders")
+	if err != nil {
+		return nil, err
+	}
+	return &flatArrayStore{path: path}, nil
+}
@@ -0,0 +1,70 @@
+package claude
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/config/permguard"
+)
+
+// writeFileAtomic writes data to a temp file in the same directory as path
+// and renames it into place, so a crash mid-write can't leave a partial or
+// world-readable half-file at path. It follows the same write-to-temp-then-
+// rename pattern internal/config/atomicjson uses; kept local since this
+// package doesn't otherwise need atomicjson's lock-guarded
+// read-modify-write (EnsureSettings/MergeSettings never have two processes
+// racing to write the same role's settings.json).
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// VerifySettingsPermissions checks that dir/.claude and its settings.json
+// still have the restrictive permissions EnsureSettings created them with
+// (0700/0600). EnsureSettings and MergeSettings already run this check in
+// warn-only mode whenever they find a settings file already on disk;
+// VerifySettingsPermissions exists for a caller (e.g. a `gt doctor` check)
+// that wants strict to turn a widened file into a hard error instead of a
+// logged warning.
+func VerifySettingsPermissions(dir string, strict bool) error {
+	return verifySettingsPermissionsAt(dir, ".claude", "settings.json", strict)
+}
+
+func verifySettingsPermissionsAt(workDir, settingsDir, settingsFile string, strict bool) error {
+	if settingsDir == "" {
+		settingsDir = "."
+	}
+	path := filepath.Join(workDir, settingsDir, settingsFile)
+
+	if err := permguard.CheckDir(filepath.Dir(path)); err != nil {
+		if !strict {
+			return permguard.Warn(err)
+		}
+		return err
+	}
+	if err := permguard.CheckFile(path); err != nil {
+		if !strict {
+			return permguard.Warn(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/managedmeta"
+	"github.com/steveyegge/gastown/internal/schema"
+)
+
+// MergeOptions controls a MergeSettings call.
+type MergeOptions struct {
+	// DryRun reports what MergeSettings would change without writing
+	// anything or creating a backup.
+	DryRun bool
+}
+
+// HookChange describes one managed hook entry MergeSettings added,
+// upgraded, or pruned.
+type HookChange struct {
+	Event  string
+	ID     string
+	Action string // "added", "changed", or "removed"
+}
+
+// MergeReport summarizes what a MergeSettings call did, or -- for a dry
+// run -- would do.
+type MergeReport struct {
+	Changed    bool
+	Changes    []HookChange
+	BackupPath string
+}
+
+// MergeSettings is EnsureSettings's upgrade path: EnsureSettings never
+// touches a settings.json that already exists, so a role created before a
+// new managed hook shipped -- or one whose RoleTypeFor classification
+// changed -- never receives it. MergeSettings parses the existing file,
+// ensures the managed hook entries for role are present and at their
+// current schema version, prunes a managed entry that's no longer
+// required, and leaves every untagged (user-authored) entry alone. A
+// changed file is backed up to settingsFile+".bak" before being
+// overwritten; opts.DryRun skips both the backup and the write, returning
+// only the report.
+func MergeSettings(dir, role string, opts MergeOptions) (*MergeReport, error) {
+	return MergeSettingsAt(dir, ".claude", "settings.json", role, opts)
+}
+
+// MergeSettingsAt is MergeSettings with the settings file's location
+// overridable, for tests and non-default layouts.
+func MergeSettingsAt(workDir, settingsDir, settingsFile, role string, opts MergeOptions) (*MergeReport, error) {
+	if settingsFile == "" {
+		return nil, errors.New("claude: settings file name is required")
+	}
+	if settingsDir == "" {
+		settingsDir = "."
+	}
+	path := filepath.Join(workDir, settingsDir, settingsFile)
+
+	existing, existed, err := readSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	if existed {
+		_ = verifySettingsPermissionsAt(workDir, settingsDir, settingsFile, false)
+	}
+
+	required := requiredSettings(RoleTypeFor(role))
+	report := mergeSettingsDocs(existing, required)
+	if !report.Changed || opts.DryRun {
+		return report, nil
+	}
+
+	if existed {
+		backupPath := path + ".bak"
+		if err := copyFile(path, backupPath); err != nil {
+			return nil, fmt.Errorf("backing up %s: %w", path, err)
+		}
+		report.BackupPath = backupPath
+	}
+
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding settings: %w", err)
+	}
+	if err := schema.Validate(schema.ClaudeSettings, data); err != nil {
+		return nil, fmt.Errorf("refusing to write %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating settings directory: %w", err)
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// readSettings loads path's settings.json, reporting an empty document
+// (rather than an error) for a missing file, and whether the file existed.
+// It validates against schema.ClaudeSettings only when the file already
+// carries the current version -- a pre-migration (legacy, version-less)
+// file hasn't been upgraded yet, so it wouldn't match the current schema,
+// and that's expected rather than a malformed file.
+func readSettings(path string) (map[string]any, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]any{}, false, nil
+		}
+		return nil, false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	doc := map[string]any{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, false, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if v, _ := doc["version"].(float64); int(v) == schema.CurrentTrustConfigVersion {
+			if err := schema.Validate(schema.ClaudeSettings, data); err != nil {
+				return nil, false, fmt.Errorf("%s does not match the %s schema: %w", path, schema.ClaudeSettings, err)
+			}
+		}
+	}
+	return doc, true, nil
+}
+
+// mergeSettingsDocs folds required's hooks into existing in place,
+// reporting what changed. It applies the same managed/unmanaged split
+// internal/copilot's mergeHooks uses for hooks.json: an existing managed
+// entry with the same id is replaced whenever its content differs from
+// required (whether that's because the hook's schema_version moved
+// forward or because the role's RoleType -- and so its command -- changed
+// since the entry was written), a managed entry whose id has dropped out
+// of required is pruned, and untagged entries are never touched.
+func mergeSettingsDocs(existing, required map[string]any) *MergeReport {
+	report := &MergeReport{}
+
+	if v, _ := existing["version"].(float64); int(v) != schema.CurrentTrustConfigVersion {
+		existing["version"] = schema.CurrentTrustConfigVersion
+		report.Changed = true
+	}
+
+	requiredHooks, _ := required["hooks"].(map[string]any)
+	if len(requiredHooks) == 0 {
+		return report
+	}
+
+	existingHooks, _ := existing["hooks"].(map[string]any)
+	if existingHooks == nil {
+		existingHooks = map[string]any{}
+	}
+
+	for event, rawGroups := range requiredHooks {
+		requiredGroups, _ := rawGroups.([]any)
+		existingGroups, _ := existingHooks[event].([]any)
+
+		merged, changes := mergeHookGroups(event, existingGroups, requiredGroups)
+		if len(changes) > 0 {
+			report.Changed = true
+			report.Changes = append(report.Changes, changes...)
+		}
+		existingHooks[event] = merged
+	}
+
+	if report.Changed {
+		existing["hooks"] = existingHooks
+	}
+	return report
+}
+
+// mergeHookGroups merges required's matcher groups for one event into
+// existing. Gas Town only ever writes a single "matcher": "" group per
+// event, so merging happens at the level of the individual hook entries
+// inside one group's hooks rather than whole groups (see findManagedGroup
+// for which group that is). User-authored entries in that group are kept,
+// and any other group is left untouched.
+func mergeHookGroups(event string, existingGroups, requiredGroups []any) ([]any, []HookChange) {
+	if len(requiredGroups) == 0 {
+		return existingGroups, nil
+	}
+	requiredGroup, _ := requiredGroups[0].(map[string]any)
+	requiredEntries, _ := requiredGroup["hooks"].([]any)
+
+	groupIdx, existingEntries := findManagedGroup(existingGroups, requiredEntries)
+	mergedEntries, changes := mergeHookEntries(event, existingEntries, requiredEntries)
+
+	if groupIdx >= 0 {
+		group, _ := existingGroups[groupIdx].(map[string]any)
+		group["hooks"] = mergedEntries
+		existingGroups[groupIdx] = group
+		return existingGroups, changes
+	}
+
+	// The event has no matcher groups at all yet; create one matching
+	// required's matcher so the merged entries have somewhere to live.
+	newGroup := map[string]any{
+		"matcher": requiredGroup["matcher"],
+		"hooks":   mergedEntries,
+	}
+	return append(existingGroups, newGroup), changes
+}
+
+// findManagedGroup returns the index of the first group in existingGroups
+// that already contains a Gas Town-managed entry matching one of
+// requiredEntries' ids, along with that group's current hooks slice. When
+// no group is managed yet it falls back to the first group, and it reports
+// -1 only when existingGroups is empty.
+func findManagedGroup(existingGroups []any, requiredEntries []any) (int, []any) {
+	requiredIDs := map[string]bool{}
+	for _, raw := range requiredEntries {
+		entry, _ := raw.(map[string]any)
+		if meta, ok := managedmeta.Of(entry); ok {
+			requiredIDs[meta.ID] = true
+		}
+	}
+
+	for i, raw := range existingGroups {
+		group, _ := raw.(map[string]any)
+		entries, _ := group["hooks"].([]any)
+		for _, rawEntry := range entries {
+			entry, _ := rawEntry.(map[string]any)
+			if meta, ok := managedmeta.Of(entry); ok && requiredIDs[meta.ID] {
+				return i, entries
+			}
+		}
+	}
+	if len(existingGroups) == 0 {
+		return -1, nil
+	}
+	// Fall back to the first group, matching internal/copilot's
+	// assumption that Gas Town owns at most one matcher group per event.
+	group, _ := existingGroups[0].(map[string]any)
+	entries, _ := group["hooks"].([]any)
+	return 0, entries
+}
+
+// mergeHookEntries applies the managed-merge rules to one matcher group's
+// hooks list.
+func mergeHookEntries(event string, existingEntries, requiredEntries []any) ([]any, []HookChange) {
+	var changes []HookChange
+	requiredIDs := map[string]bool{}
+
+	for _, raw := range requiredEntries {
+		required, _ := raw.(map[string]any)
+		meta, ok := managedmeta.Of(required)
+		if !ok {
+			continue
+		}
+		requiredIDs[meta.ID] = true
+
+		if idx := findManagedEntry(existingEntries, meta.ID); idx >= 0 {
+			existingEntry, _ := existingEntries[idx].(map[string]any)
+			if !entriesEqual(existingEntry, required) {
+				existingEntries[idx] = required
+				changes = append(changes, HookChange{Event: event, ID: meta.ID, Action: "changed"})
+			}
+			continue
+		}
+
+		existingEntries = append(existingEntries, required)
+		changes = append(changes, HookChange{Event: event, ID: meta.ID, Action: "added"})
+	}
+
+	pruned := existingEntries[:0]
+	for _, raw := range existingEntries {
+		entry, _ := raw.(map[string]any)
+		if meta, ok := managedmeta.Of(entry); ok && meta.Owner == managedmeta.GastownOwner && !requiredIDs[meta.ID] {
+			changes = append(changes, HookChange{Event: event, ID: meta.ID, Action: "removed"})
+			continue
+		}
+		pruned = append(pruned, raw)
+	}
+	return pruned, changes
+}
+
+// findManagedEntry returns the index of the Gas Town-managed entry
+// carrying id, or -1 if none does.
+func findManagedEntry(entries []any, id string) int {
+	for i, raw := range entries {
+		entry, _ := raw.(map[string]any)
+		if meta, ok := managedmeta.Of(entry); ok && meta.Owner == managedmeta.GastownOwner && meta.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// entriesEqual reports whether two hook entries are byte-for-byte
+// identical once marshaled, so a no-op merge (nothing about the required
+// hook changed) doesn't get reported as a change.
+func entriesEqual(a, b map[string]any) bool {
+	aData, errA := json.Marshal(a)
+	bData, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(aData) == string(bData)
+}
+
+// copyFile copies src to dst, preserving src's permission bits. Used to
+// leave a .bak alongside a settings.json MergeSettings is about to
+// overwrite.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,245 @@
+package claude
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/managedmeta"
+	"github.com/steveyegge/gastown/internal/schema"
+)
+
+func writeSettingsFile(t *testing.T, path string, doc map[string]any) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func readSettingsFile(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return doc
+}
+
+func TestMergeSettings_UserOnlyHooksUntouched(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	writeSettingsFile(t, settingsPath, map[string]any{
+		"hooks": map[string]any{
+			"SessionStart": []any{
+				map[string]any{
+					"matcher": "",
+					"hooks": []any{
+						map[string]any{"type": "command", "command": "echo user-hook"},
+					},
+				},
+			},
+		},
+	})
+
+	report, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+	if !report.Changed {
+		t.Fatal("expected MergeSettings to add the managed hook")
+	}
+
+	doc := readSettingsFile(t, settingsPath)
+	groups := doc["hooks"].(map[string]any)["SessionStart"].([]any)
+	hooks := groups[0].(map[string]any)["hooks"].([]any)
+	if len(hooks) != 2 {
+		t.Fatalf("expected user hook preserved alongside managed hook, got %d entries: %v", len(hooks), hooks)
+	}
+
+	foundUser, foundManaged := false, false
+	for _, raw := range hooks {
+		entry := raw.(map[string]any)
+		if entry["command"] == "echo user-hook" {
+			foundUser = true
+		}
+		if meta, ok := managedmeta.Of(entry); ok && meta.ID == "session-start" {
+			foundManaged = true
+		}
+	}
+	if !foundUser {
+		t.Error("expected the user's own hook to survive the merge")
+	}
+	if !foundManaged {
+		t.Error("expected a managed session-start hook to be added")
+	}
+}
+
+func TestMergeSettings_UpgradesStaleManagedHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	stale := managedmeta.With(map[string]any{
+		"type":    "command",
+		"command": "gt prime && gt nudge deacon session-started",
+	}, managedmeta.Meta{Owner: managedmeta.GastownOwner, ID: "session-start", SchemaVersion: 0})
+
+	writeSettingsFile(t, settingsPath, map[string]any{
+		"hooks": map[string]any{
+			"SessionStart": []any{
+				map[string]any{"matcher": "", "hooks": []any{stale}},
+			},
+		},
+	})
+
+	report, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+	if !report.Changed {
+		t.Fatal("expected MergeSettings to upgrade the stale managed hook")
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Action != "changed" {
+		t.Fatalf("expected a single 'changed' entry, got %+v", report.Changes)
+	}
+	if report.BackupPath == "" {
+		t.Error("expected a .bak path to be recorded")
+	}
+	if _, err := os.Stat(report.BackupPath); err != nil {
+		t.Errorf("expected a backup file at %s: %v", report.BackupPath, err)
+	}
+
+	doc := readSettingsFile(t, settingsPath)
+	groups := doc["hooks"].(map[string]any)["SessionStart"].([]any)
+	hooks := groups[0].(map[string]any)["hooks"].([]any)
+	if len(hooks) != 1 {
+		t.Fatalf("expected exactly 1 hook after upgrade, got %d: %v", len(hooks), hooks)
+	}
+	if hooks[0].(map[string]any)["command"] != "gt prime && gt mail check --inject && gt nudge deacon session-started" {
+		t.Errorf("expected the upgraded autonomous command, got %v", hooks[0])
+	}
+}
+
+func TestMergeSettings_RoleChangeInteractiveToAutonomous(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	if err := EnsureSettings(tmpDir, Interactive); err != nil {
+		t.Fatalf("EnsureSettings: %v", err)
+	}
+
+	report, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+	if !report.Changed {
+		t.Fatal("expected MergeSettings to pick up the autonomous mail-inject hook")
+	}
+
+	doc := readSettingsFile(t, settingsPath)
+	groups := doc["hooks"].(map[string]any)["SessionStart"].([]any)
+	hooks := groups[0].(map[string]any)["hooks"].([]any)
+	if len(hooks) != 1 {
+		t.Fatalf("expected the interactive hook to be replaced in place, got %d entries: %v", len(hooks), hooks)
+	}
+	if hooks[0].(map[string]any)["command"] != "gt prime && gt mail check --inject && gt nudge deacon session-started" {
+		t.Errorf("expected the autonomous command after role change, got %v", hooks[0])
+	}
+
+	// Same schema_version (both Interactive and Autonomous share
+	// settingsSchemaVersion) but a different command: the merge must still
+	// replace it, so a second call should be a no-op.
+	report2, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err != nil {
+		t.Fatalf("second MergeSettings: %v", err)
+	}
+	if report2.Changed {
+		t.Errorf("expected the second merge to be a no-op, got changes: %+v", report2.Changes)
+	}
+}
+
+func TestMergeSettings_LegacyFileGetsVersionStamped(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	// A pre-migration settings.json never carried a top-level "version".
+	writeSettingsFile(t, settingsPath, map[string]any{
+		"hooks": map[string]any{},
+	})
+
+	report, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+	if !report.Changed {
+		t.Fatal("expected MergeSettings to stamp a version and add the managed hook")
+	}
+
+	doc := readSettingsFile(t, settingsPath)
+	if v, _ := doc["version"].(float64); int(v) != schema.CurrentTrustConfigVersion {
+		t.Errorf("version = %v, want %d", doc["version"], schema.CurrentTrustConfigVersion)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := schema.Validate(schema.ClaudeSettings, data); err != nil {
+		t.Fatalf("merged settings.json fails schema.Validate: %v", err)
+	}
+}
+
+func TestMergeSettings_RejectsMalformedCurrentVersionFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	// A version-2 file whose additionalDirectories entries are missing the
+	// required "path" field doesn't match schema.ClaudeSettings -- this is
+	// the malformed-hand-edit case schema.Validate exists to catch, as
+	// opposed to a legacy pre-version file that just hasn't migrated yet.
+	writeSettingsFile(t, settingsPath, map[string]any{
+		"version": schema.CurrentTrustConfigVersion,
+		"permissions": map[string]any{
+			"additionalDirectories": []any{
+				map[string]any{"added_at": "2026-01-01T00:00:00Z"},
+			},
+		},
+	})
+
+	_, err := MergeSettings(tmpDir, "polecat", MergeOptions{})
+	if err == nil {
+		t.Fatal("expected MergeSettings to reject a malformed version-2 settings.json")
+	}
+	if !strings.Contains(err.Error(), "schema") {
+		t.Errorf("expected a schema-related error, got: %v", err)
+	}
+}
+
+func TestMergeSettings_DryRunWritesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	report, err := MergeSettings(tmpDir, "witness", MergeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MergeSettings: %v", err)
+	}
+	if !report.Changed {
+		t.Fatal("expected a dry run report to still flag the pending change")
+	}
+	if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+		t.Errorf("expected no settings.json to be written on a dry run, stat err=%v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package claude
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config/permguard"
+)
+
+func TestEnsureSettings_WritesRestrictivePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	tmpDir := t.TempDir()
+
+	if err := EnsureSettings(tmpDir, Autonomous); err != nil {
+		t.Fatalf("EnsureSettings: %v", err)
+	}
+
+	claudeDir := filepath.Join(tmpDir, ".claude")
+	dirInfo, err := os.Stat(claudeDir)
+	if err != nil {
+		t.Fatalf("stat %s: %v", claudeDir, err)
+	}
+	if perm := dirInfo.Mode().Perm(); perm != permguard.MaxDirMode {
+		t.Errorf(".claude mode = %04o, want %04o", perm, permguard.MaxDirMode)
+	}
+
+	settingsPath := filepath.Join(claudeDir, "settings.json")
+	fileInfo, err := os.Stat(settingsPath)
+	if err != nil {
+		t.Fatalf("stat %s: %v", settingsPath, err)
+	}
+	if perm := fileInfo.Mode().Perm(); perm != permguard.MaxFileMode {
+		t.Errorf("settings.json mode = %04o, want %04o", perm, permguard.MaxFileMode)
+	}
+}
+
+func TestEnsureSettings_WidenedExistingFileWarnsOnStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(settingsPath, []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	stderr := captureStderr(t, func() {
+		if err := EnsureSettings(tmpDir, Autonomous); err != nil {
+			t.Fatalf("EnsureSettings: %v", err)
+		}
+	})
+
+	if !bytes.Contains(stderr, []byte(settingsPath)) {
+		t.Errorf("expected a permission warning mentioning %s on stderr, got %q", settingsPath, stderr)
+	}
+
+	// EnsureSettings must still leave the pre-existing file untouched --
+	// warning about widened permissions isn't license to overwrite it.
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "{}" {
+		t.Errorf("expected the pre-existing file to be left alone, got %q", data)
+	}
+}
+
+func TestVerifySettingsPermissions_StrictRefusesWidenedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	tmpDir := t.TempDir()
+	if err := EnsureSettings(tmpDir, Interactive); err != nil {
+		t.Fatalf("EnsureSettings: %v", err)
+	}
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	if err := os.Chmod(settingsPath, 0644); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+
+	if err := VerifySettingsPermissions(tmpDir, false); err != nil {
+		t.Errorf("non-strict VerifySettingsPermissions should warn, not error, got %v", err)
+	}
+	if err := VerifySettingsPermissions(tmpDir, true); err == nil {
+		t.Error("expected strict VerifySettingsPermissions to refuse a widened file")
+	}
+}
+
+// captureStderr runs fn with os.Stderr redirected to a pipe and returns
+// whatever fn wrote to it.
+func captureStderr(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = orig }()
+
+	fn()
+
+	w.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		t.Fatalf("reading captured stderr: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,129 @@
+// Package claude manages the .claude/settings.json file Gas Town installs
+// so Claude Code picks up its SessionStart hook and working-directory
+// trust without the user having to configure anything by hand.
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/managedmeta"
+	"github.com/steveyegge/gastown/internal/schema"
+)
+
+// RoleType distinguishes autonomous Gas Town roles, which run unattended
+// and need mail injected on session start, from interactive roles driven
+// by a human who would rather not have mail injected automatically.
+type RoleType int
+
+const (
+	Interactive RoleType = iota
+	Autonomous
+)
+
+// RoleTypeFor classifies role into a RoleType. Unknown roles default to
+// Interactive, matching the conservative default internal/runtime's
+// StartupFallbackCommands uses for the same roster.
+func RoleTypeFor(role string) RoleType {
+	switch role {
+	case "polecat", "witness", "refinery", "deacon":
+		return Autonomous
+	default:
+		return Interactive
+	}
+}
+
+// settingsSchemaVersion is the schema_version stamped on every managed
+// hook entry EnsureSettings/MergeSettings write. Bump it whenever
+// requiredSettings's hook command changes shape. MergeSettings replaces an
+// existing managed entry with the same id whenever its content differs
+// from the required one, so a changed command is upgraded in place rather
+// than left as a stale duplicate.
+const settingsSchemaVersion = 1
+
+// EnsureSettings creates dir/.claude/settings.json for role type rt if no
+// settings file exists yet. It never touches a file that's already there,
+// including one this package wrote earlier -- see MergeSettings for
+// upgrading an existing file's managed hooks in place.
+func EnsureSettings(dir string, rt RoleType) error {
+	return EnsureSettingsAt(dir, rt, ".claude", "settings.json")
+}
+
+// EnsureSettingsForRoleAt is EnsureSettingsAt with role resolved via
+// RoleTypeFor, for callers (see internal/runtime.EnsureSettingsForRole)
+// that only know the role name.
+func EnsureSettingsForRoleAt(workDir, role, settingsDir, settingsFile string) error {
+	return EnsureSettingsAt(workDir, RoleTypeFor(role), settingsDir, settingsFile)
+}
+
+// EnsureSettingsAt is EnsureSettings with the settings file's location
+// overridable, for tests and non-default layouts. An empty settingsDir
+// writes directly to workDir; an empty settingsFile is an error, since
+// there'd be nothing to not-overwrite.
+func EnsureSettingsAt(workDir string, rt RoleType, settingsDir, settingsFile string) error {
+	if settingsFile == "" {
+		return errors.New("claude: settings file name is required")
+	}
+	if settingsDir == "" {
+		settingsDir = "."
+	}
+
+	path := filepath.Join(workDir, settingsDir, settingsFile)
+	if _, err := os.Stat(path); err == nil {
+		_ = verifySettingsPermissionsAt(workDir, settingsDir, settingsFile, false)
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("checking %s: %w", path, err)
+	}
+
+	data, err := json.MarshalIndent(requiredSettings(rt), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding settings: %w", err)
+	}
+	if err := schema.Validate(schema.ClaudeSettings, data); err != nil {
+		return fmt.Errorf("claude: built-in settings don't match the %s schema: %w", schema.ClaudeSettings, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating settings directory: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// requiredSettings builds the settings.json Gas Town wants present for rt,
+// with every hook entry it owns tagged as managed (see managedmeta.Meta) so
+// a later MergeSettings call can tell it apart from a user's own hooks. It
+// deliberately uses the same map[string]any/[]any shapes json.Unmarshal
+// would produce (rather than concrete slice/struct types) so
+// mergeSettingsDocs can treat a freshly-built required doc and one read
+// back off disk identically. It stamps "version": schema.CurrentTrustConfigVersion
+// at the document root -- the same field agenttrust's Claude store stamps
+// on its trust writes to this same file -- so the two always agree on one
+// version number and a settings.json this package writes always validates
+// against schema.ClaudeSettings.
+func requiredSettings(rt RoleType) map[string]any {
+	command := "gt prime"
+	if rt == Autonomous {
+		command += " && gt mail check --inject"
+	}
+	command += " && gt nudge deacon session-started"
+
+	sessionStartHook := managedmeta.With(map[string]any{
+		"type":    "command",
+		"command": command,
+	}, managedmeta.Meta{Owner: managedmeta.GastownOwner, ID: "session-start", SchemaVersion: settingsSchemaVersion})
+
+	return map[string]any{
+		"version": schema.CurrentTrustConfigVersion,
+		"hooks": map[string]any{
+			"SessionStart": []any{
+				map[string]any{
+					"matcher": "",
+					"hooks":   []any{sessionStartHook},
+				},
+			},
+		},
+	}
+}
@@ -1,10 +1,13 @@
 package claude
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/schema"
 )
 
 func TestRoleTypeFor(t *testing.T) {
@@ -106,6 +109,30 @@ func TestEnsureSettings_DoesNotOverwriteExisting(t *testing.T) {
 	}
 }
 
+func TestEnsureSettings_WritesValidClaudeSettingsSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := EnsureSettings(tmpDir, Autonomous); err != nil {
+		t.Fatalf("EnsureSettings() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".claude", "settings.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if err := schema.Validate(schema.ClaudeSettings, data); err != nil {
+		t.Fatalf("EnsureSettings wrote a settings.json that fails schema.Validate: %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v, _ := doc["version"].(float64); int(v) != schema.CurrentTrustConfigVersion {
+		t.Errorf("version = %v, want %d", doc["version"], schema.CurrentTrustConfigVersion)
+	}
+}
+
 func TestEnsureSettingsAt_EmptySettingsDir(t *testing.T) {
 	tmpDir := t.TempDir()
 
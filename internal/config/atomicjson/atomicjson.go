@@ -0,0 +1,150 @@
+// Package atomicjson provides lock-guarded, atomic read-modify-write helpers
+// for small JSON config files that may be touched by multiple Gas Town
+// processes concurrently (boot, mayor, witness, refinery, deacon, polecats).
+package atomicjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+
+	"github.com/steveyegge/gastown/internal/config/fsys"
+)
+
+// Update reads the JSON object at path (treating a missing file as an empty
+// object), passes it to mutate, and writes the result back atomically if
+// mutate reports a change. The read-modify-write is guarded by an advisory
+// OS-level lock on path+".lock" so concurrent callers serialize instead of
+// racing each other. The actual read/mutate/write goes through UpdateFS
+// against the real disk (fsys.Default), the same function this package's
+// tests run against an injected fsys.FS -- so the production write and
+// those tests exercise identical read-modify-write logic.
+//
+// mutate receives the decoded object and returns (changed, error). When
+// changed is false, Update leaves the file untouched.
+func Update(path string, mutate func(cfg map[string]any) (bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer lock.Unlock()
+
+	return UpdateFS(fsys.Default, path, mutate)
+}
+
+// UpdateStringArray is Update's sibling for config files whose JSON root is
+// itself a string array (e.g. a flat trusted_folders list) rather than an
+// object. It reads the array at path (treating a missing file as empty),
+// passes it to mutate, and writes the result back atomically if mutate
+// reports a change, under the same path+".lock" advisory lock Update uses.
+// The actual read/mutate/write goes through UpdateStringArrayFS against the
+// real disk (fsys.Default), the same function this package's tests
+// exercise against an injected fsys.FS.
+func UpdateStringArray(path string, mutate func(entries []string) ([]string, bool, error)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer lock.Unlock()
+
+	return UpdateStringArrayFS(fsys.Default, path, mutate)
+}
+
+// UpdateStringArrayFS is UpdateStringArray's sibling for callers that need
+// to inject a fake filesystem (see internal/config/fsys), mirroring how
+// UpdateFS relates to Update. It doesn't take the advisory file lock
+// UpdateStringArray does, for the same reason UpdateFS doesn't.
+func UpdateStringArrayFS(fs fsys.FS, path string, mutate func(entries []string) ([]string, bool, error)) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	var entries []string
+	if data, err := fs.ReadFile(path); err == nil {
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &entries); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	updated, changed, err := mutate(entries)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := fs.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := fs.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming into place: %w", err)
+	}
+	return nil
+}
+
+// UpdateFS is Update's sibling for callers that need to inject a fake
+// filesystem (see internal/config/fsys), e.g. to exercise ENOSPC or
+// read-only-directory failures in tests. It doesn't take the advisory
+// file lock Update does, since fsys.FS implementations are exercised
+// single-threaded or already self-serializing (MemFS holds an internal
+// mutex across its calls).
+func UpdateFS(fs fsys.FS, path string, mutate func(cfg map[string]any) (bool, error)) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	cfg := map[string]any{}
+	if data, err := fs.ReadFile(path); err == nil {
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	changed, err := mutate(cfg)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := fs.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := fs.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming into place: %w", err)
+	}
+	return nil
+}
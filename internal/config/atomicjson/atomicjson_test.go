@@ -0,0 +1,178 @@
+package atomicjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpdate_CreatesFileWhenMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	err := Update(path, func(cfg map[string]any) (bool, error) {
+		cfg["hello"] = "world"
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg["hello"] != "world" {
+		t.Fatalf("expected hello=world, got %v", cfg)
+	}
+}
+
+func TestUpdate_PreservesExistingKeysAndSkipsNoopWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"existing":"value"}`), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+
+	err = Update(path, func(cfg map[string]any) (bool, error) {
+		if cfg["existing"] != "value" {
+			t.Fatalf("expected existing key to be preserved, got: %v", cfg)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat after: %v", err)
+	}
+	if after.ModTime() != info.ModTime() {
+		t.Fatal("expected no-op mutate to leave the file untouched")
+	}
+}
+
+func TestUpdate_ConcurrentCallersEachAddOneEntryExactlyOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	const n = 32
+	var wg sync.WaitGroup
+	errCh := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			entry := fmt.Sprintf("/work/dir-%d", i)
+			errCh <- Update(path, func(cfg map[string]any) (bool, error) {
+				var entries []string
+				if raw, ok := cfg["trusted_folders"].([]any); ok {
+					for _, v := range raw {
+						if s, ok := v.(string); ok {
+							entries = append(entries, s)
+						}
+					}
+				}
+				entries = append(entries, entry)
+				cfg["trusted_folders"] = entries
+				return true, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v (data: %s)", err, data)
+	}
+	entries, ok := cfg["trusted_folders"].([]any)
+	if !ok {
+		t.Fatalf("expected trusted_folders array, got: %v", cfg)
+	}
+
+	seen := map[string]int{}
+	for _, e := range entries {
+		if s, ok := e.(string); ok {
+			seen[s]++
+		}
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d unique entries, got %d: %v", n, len(seen), seen)
+	}
+	for entry, count := range seen {
+		if count != 1 {
+			t.Fatalf("entry %q appeared %d times, want 1", entry, count)
+		}
+	}
+}
+
+func TestUpdateStringArray_CreatesFileWhenMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trusted_folders")
+
+	err := UpdateStringArray(path, func(entries []string) ([]string, bool, error) {
+		return append(entries, "/work/dir"), true, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateStringArray: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(entries) != 1 || entries[0] != "/work/dir" {
+		t.Fatalf("expected [\"/work/dir\"], got %v", entries)
+	}
+}
+
+func TestUpdateStringArray_SkipsNoopWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trusted_folders")
+	if err := os.WriteFile(path, []byte(`["/already/trusted"]`), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+
+	err = UpdateStringArray(path, func(entries []string) ([]string, bool, error) {
+		if len(entries) != 1 || entries[0] != "/already/trusted" {
+			t.Fatalf("expected existing entry to be preserved, got: %v", entries)
+		}
+		return entries, false, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateStringArray: %v", err)
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat after: %v", err)
+	}
+	if after.ModTime() != info.ModTime() {
+		t.Fatal("expected no-op mutate to leave the file untouched")
+	}
+}
@@ -0,0 +1,209 @@
+package atomicjson
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config/fsys"
+)
+
+func TestUpdateFS_CreatesAndMutates(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.copilot/config.json"
+
+	err := UpdateFS(mem, path, func(cfg map[string]any) (bool, error) {
+		cfg["trusted_folders"] = []string{"/work/a"}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateFS: %v", err)
+	}
+
+	data, err := mem.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected config to be written")
+	}
+}
+
+func TestUpdateFS_CaseInsensitiveCollision(t *testing.T) {
+	mem := fsys.NewMemFS()
+	mem.SetCaseInsensitive(true)
+
+	path := "/Users/dev/.copilot/config.json"
+	if err := UpdateFS(mem, path, func(cfg map[string]any) (bool, error) {
+		cfg["seen"] = true
+		return true, nil
+	}); err != nil {
+		t.Fatalf("UpdateFS: %v", err)
+	}
+
+	// Same logical path with different casing should see the file we just wrote.
+	differentCase := "/users/DEV/.COPILOT/config.json"
+	data, err := mem.ReadFile(differentCase)
+	if err != nil {
+		t.Fatalf("ReadFile with different case: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected case-insensitive read to find the written config")
+	}
+}
+
+func TestUpdateFS_ENOSPCMidWrite(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.copilot/config.json"
+	mem.FailNext("WriteFile", path+".tmp", fsys.ErrNoSpace)
+
+	err := UpdateFS(mem, path, func(cfg map[string]any) (bool, error) {
+		cfg["trusted_folders"] = []string{"/work/a"}
+		return true, nil
+	})
+	if !errors.Is(err, fsys.ErrNoSpace) {
+		t.Fatalf("expected ErrNoSpace, got %v", err)
+	}
+
+	// The real file must not have been created by the failed write.
+	if _, err := mem.ReadFile(path); err == nil {
+		t.Fatal("expected config.json to remain absent after a failed write")
+	}
+}
+
+func TestUpdateFS_ReadOnlyConfigDir(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.copilot/config.json"
+	mem.FailNext("MkdirAll", "/home/user/.copilot", fsys.ErrNoSpace)
+
+	err := UpdateFS(mem, path, func(cfg map[string]any) (bool, error) {
+		cfg["trusted_folders"] = []string{"/work/a"}
+		return true, nil
+	})
+	if err == nil {
+		t.Fatal("expected error when config directory can't be created")
+	}
+}
+
+func TestUpdateStringArrayFS_CreatesAndMutates(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.cursor/trusted_folders"
+
+	err := UpdateStringArrayFS(mem, path, func(entries []string) ([]string, bool, error) {
+		return append(entries, "/work/a"), true, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateStringArrayFS: %v", err)
+	}
+
+	data, err := mem.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected trusted_folders to be written")
+	}
+}
+
+func TestUpdateStringArrayFS_ENOSPCMidWrite(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.cursor/trusted_folders"
+	mem.FailNext("WriteFile", path+".tmp", fsys.ErrNoSpace)
+
+	err := UpdateStringArrayFS(mem, path, func(entries []string) ([]string, bool, error) {
+		return append(entries, "/work/a"), true, nil
+	})
+	if !errors.Is(err, fsys.ErrNoSpace) {
+		t.Fatalf("expected ErrNoSpace, got %v", err)
+	}
+
+	// The real file must not have been created by the failed write.
+	if _, err := mem.ReadFile(path); err == nil {
+		t.Fatal("expected trusted_folders to remain absent after a failed write")
+	}
+}
+
+func TestUpdateStringArrayFS_ReadOnlyConfigDir(t *testing.T) {
+	mem := fsys.NewMemFS()
+	path := "/home/user/.cursor/trusted_folders"
+	mem.FailNext("MkdirAll", "/home/user/.cursor", fsys.ErrNoSpace)
+
+	err := UpdateStringArrayFS(mem, path, func(entries []string) ([]string, bool, error) {
+		return append(entries, "/work/a"), true, nil
+	})
+	if err == nil {
+		t.Fatal("expected error when config directory can't be created")
+	}
+}
+
+// TestUpdateStringArray_RoutesThroughUpdateStringArrayFS is
+// TestUpdate_RoutesThroughUpdateFS's sibling for the flat-array path used
+// by Cursor, Windsurf, Codex, and Auggie's trust stores: the production
+// UpdateStringArray entry point must share UpdateStringArrayFS's
+// read-modify-write logic rather than a parallel copy of it.
+func TestUpdateStringArray_RoutesThroughUpdateStringArrayFS(t *testing.T) {
+	mutate := func(entries []string) ([]string, bool, error) {
+		return append(entries, "/work/a", "/work/b"), true, nil
+	}
+
+	diskPath := filepath.Join(t.TempDir(), "trusted_folders")
+	if err := UpdateStringArray(diskPath, mutate); err != nil {
+		t.Fatalf("UpdateStringArray: %v", err)
+	}
+	diskData, err := os.ReadFile(diskPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	mem := fsys.NewMemFS()
+	memPath := "/home/user/.cursor/trusted_folders"
+	if err := UpdateStringArrayFS(mem, memPath, mutate); err != nil {
+		t.Fatalf("UpdateStringArrayFS: %v", err)
+	}
+	memData, err := mem.ReadFile(memPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if string(diskData) != string(memData) {
+		t.Fatalf("UpdateStringArray and UpdateStringArrayFS produced different output:\ndisk: %s\nmem:  %s", diskData, memData)
+	}
+}
+
+// TestUpdate_RoutesThroughUpdateFS pins down that the production Update
+// entry point shares UpdateFS's read-modify-write logic rather than a
+// parallel copy of it: a real-disk Update and an UpdateFS call against a
+// MemFS, given the same mutate func, must produce identical JSON. If
+// Update ever regresses back to its own inline implementation, this test
+// catches the divergence even though the two run against different
+// filesystems.
+func TestUpdate_RoutesThroughUpdateFS(t *testing.T) {
+	mutate := func(cfg map[string]any) (bool, error) {
+		cfg["trusted_folders"] = []string{"/work/a", "/work/b"}
+		return true, nil
+	}
+
+	diskPath := filepath.Join(t.TempDir(), "config.json")
+	if err := Update(diskPath, mutate); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	diskData, err := os.ReadFile(diskPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	mem := fsys.NewMemFS()
+	memPath := "/home/user/.copilot/config.json"
+	if err := UpdateFS(mem, memPath, mutate); err != nil {
+		t.Fatalf("UpdateFS: %v", err)
+	}
+	memData, err := mem.ReadFile(memPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if string(diskData) != string(memData) {
+		t.Fatalf("Update and UpdateFS produced different output:\ndisk: %s\nmem:  %s", diskData, memData)
+	}
+}
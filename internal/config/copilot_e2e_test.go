@@ -16,7 +16,7 @@ import (
 // TestCopilotTrustE2E_ConfigCreation verifies that the Copilot config file
 // is correctly created when it doesn't exist.
 func TestCopilotTrustE2E_ConfigCreation(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -71,7 +71,7 @@ func TestCopilotTrustE2E_ConfigCreation(t *testing.T) {
 
 	foundWitness := false
 	for _, f := range folders {
-		if str, ok := f.(string); ok && str == witnessDir {
+		if entry, ok := f.(map[string]interface{}); ok && entry["path"] == witnessDir {
 			foundWitness = true
 			break
 		}
@@ -85,7 +85,7 @@ func TestCopilotTrustE2E_ConfigCreation(t *testing.T) {
 // TestCopilotTrustE2E_NoDuplicates verifies that calling trust setup
 // multiple times doesn't create duplicate entries.
 func TestCopilotTrustE2E_NoDuplicates(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -130,7 +130,7 @@ func TestCopilotTrustE2E_NoDuplicates(t *testing.T) {
 
 	count := 0
 	for _, f := range folders {
-		if str, ok := f.(string); ok && str == witnessDir {
+		if entry, ok := f.(map[string]interface{}); ok && entry["path"] == witnessDir {
 			count++
 		}
 	}
@@ -143,7 +143,6 @@ func TestCopilotTrustE2E_NoDuplicates(t *testing.T) {
 // TestCopilotTrustE2E_AllRoles verifies that all agent startup paths
 // properly trust their working directories.
 func TestCopilotTrustE2E_AllRoles(t *testing.T) {
-	
 
 	tests := []struct {
 		role       string
@@ -218,7 +217,7 @@ func TestCopilotTrustE2E_AllRoles(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.role, func(t *testing.T) {
-			
+
 			if tt.skipReason != "" {
 				t.Skip(tt.skipReason)
 			}
@@ -264,7 +263,7 @@ func TestCopilotTrustE2E_AllRoles(t *testing.T) {
 // TestCopilotTrustE2E_PolecatParentTrust specifically validates that
 // polecats trust the polecats/ parent directory, not individual worktrees.
 func TestCopilotTrustE2E_PolecatParentTrust(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -341,21 +340,37 @@ func TestCopilotTrustE2E_PolecatParentTrust(t *testing.T) {
 	}
 }
 
+// nonCopilotTrustPaths maps each non-Copilot agent exercised by
+// TestCopilotTrustE2E_NonCopilotAgent to the trust file its agenttrust
+// store writes under $HOME. Agents with no entry here (e.g. "amp") have no
+// registered store, so EnsureCopilotTrustedFolder is expected to leave
+// $HOME untouched for them.
+var nonCopilotTrustPaths = map[string]string{
+	"claude": filepath.Join(".claude", "settings.json"),
+	"gemini": filepath.Join(".gemini", "config.json"),
+	"codex":  filepath.Join(".codex", "trusted_folders"),
+	"cursor": filepath.Join(".cursor", "trusted_folders"),
+	"auggie": filepath.Join(".augment", "trusted_folders"),
+}
+
 // TestCopilotTrustE2E_NonCopilotAgent verifies that when the agent is NOT
-// Copilot, the config file is not created or modified.
+// Copilot, EnsureCopilotTrustedFolder writes that agent's own trust file
+// instead of Copilot's (or, for an agent with no registered agenttrust
+// store, writes nothing at all).
 func TestCopilotTrustE2E_NonCopilotAgent(t *testing.T) {
-	
 
 	agents := []string{"claude", "gemini", "codex", "cursor", "auggie", "amp"}
 
 	for _, agent := range agents {
 		agent := agent
 		t.Run(agent, func(t *testing.T) {
-			
+
 			tmpDir := t.TempDir()
 
 			xdgHome := filepath.Join(tmpDir, "xdg")
 			t.Setenv("XDG_CONFIG_HOME", xdgHome)
+			homeDir := filepath.Join(tmpDir, "home")
+			t.Setenv("HOME", homeDir)
 
 			townRoot, rigPath := setupTestTown(t, tmpDir, agent)
 			witnessDir := filepath.Join(rigPath, "witness")
@@ -372,20 +387,37 @@ func TestCopilotTrustE2E_NonCopilotAgent(t *testing.T) {
 				t.Fatalf("EnsureCopilotTrustedFolder failed: %v", err)
 			}
 
-			// Verify config file was NOT created
+			// Copilot's own config should never be touched by a non-Copilot agent.
 			copilotConfig := filepath.Join(xdgHome, ".copilot", "config.json")
 			if _, err := os.Stat(copilotConfig); !os.IsNotExist(err) {
 				t.Errorf("Expected no Copilot config for agent %q, but file exists", agent)
 			}
 
-			t.Logf("✓ %s agent correctly skips Copilot config", agent)
+			rel, hasStore := nonCopilotTrustPaths[agent]
+			agentConfig := filepath.Join(homeDir, rel)
+			if !hasStore {
+				if _, err := os.Stat(agentConfig); err == nil {
+					t.Errorf("Expected no trust file for agent %q with no registered store, but %s exists", agent, agentConfig)
+				}
+				t.Logf("✓ %s agent has no registered store, writes nothing", agent)
+				return
+			}
+
+			data, err := os.ReadFile(agentConfig)
+			if err != nil {
+				t.Fatalf("Expected %s to be written for agent %q: %v", agentConfig, agent, err)
+			}
+			if !strings.Contains(string(data), witnessDir) {
+				t.Errorf("Expected %q in %s. Got: %s", witnessDir, agentConfig, data)
+			}
+
+			t.Logf("✓ %s agent writes its own trust file instead of Copilot's", agent)
 		})
 	}
 }
 
 // TestCopilotTrustE2E_AgentOverride verifies that AgentOverride is respected.
 func TestCopilotTrustE2E_AgentOverride(t *testing.T) {
-	
 
 	t.Run("OverrideToCopilot", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -450,7 +482,7 @@ func TestCopilotTrustE2E_AgentOverride(t *testing.T) {
 
 // TestCopilotTrustE2E_EmptyWorkDir verifies that empty WorkDir is handled gracefully.
 func TestCopilotTrustE2E_EmptyWorkDir(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -477,7 +509,7 @@ func TestCopilotTrustE2E_EmptyWorkDir(t *testing.T) {
 
 // TestCopilotTrustE2E_DirectAPIUsage tests the lower-level copilot.EnsureTrustedFolder directly.
 func TestCopilotTrustE2E_DirectAPIUsage(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -520,7 +552,7 @@ func TestCopilotTrustE2E_DirectAPIUsage(t *testing.T) {
 
 // TestCopilotTrustE2E_MultipleDirectories verifies multiple directories can be trusted.
 func TestCopilotTrustE2E_MultipleDirectories(t *testing.T) {
-	
+
 	tmpDir := t.TempDir()
 
 	xdgHome := filepath.Join(tmpDir, "xdg")
@@ -0,0 +1,21 @@
+// Package fsys abstracts the small filesystem surface config writers need
+// (Stat/ReadFile/WriteFile/MkdirAll/Rename/Remove) behind an FS interface,
+// so tests can exercise failure modes (ENOSPC, EACCES, partial writes,
+// case-insensitive collisions) without touching the real disk.
+package fsys
+
+import "os"
+
+// FS is the minimal filesystem surface used by config writers.
+type FS interface {
+	Stat(path string) (os.FileInfo, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	MkdirAll(path string, perm os.FileMode) error
+	Rename(oldpath, newpath string) error
+	Remove(path string) error
+}
+
+// Default is the package-level FS used by callers that don't need to inject
+// a fake one, preserving the pre-fsys behavior of operating on the real disk.
+var Default FS = osFS{}
@@ -0,0 +1,161 @@
+package fsys
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoSpace mimics ENOSPC for use with FailNext.
+var ErrNoSpace = errors.New("no space left on device")
+
+// MemFS is an in-memory FS implementation for tests. It supports injecting
+// errors for specific paths/operations and case-insensitive path matching
+// (to simulate macOS/Windows filesystems).
+type MemFS struct {
+	mu             sync.Mutex
+	files          map[string][]byte
+	dirs           map[string]bool
+	caseInsensitive bool
+	errs           map[string]error // "op:path" -> error to return once
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files: map[string][]byte{},
+		dirs:  map[string]bool{"/": true},
+		errs:  map[string]error{},
+	}
+}
+
+// SetCaseInsensitive toggles case-insensitive path matching, mimicking
+// macOS/Windows default filesystems.
+func (m *MemFS) SetCaseInsensitive(insensitive bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caseInsensitive = insensitive
+}
+
+// FailNext makes the next call to op (e.g. "WriteFile") for path return err.
+// The injected error is consumed after one matching call.
+func (m *MemFS) FailNext(op, path string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs[op+":"+m.key(path)] = err
+}
+
+func (m *MemFS) key(p string) string {
+	if m.caseInsensitive {
+		return strings.ToLower(path.Clean(filepathToSlash(p)))
+	}
+	return path.Clean(filepathToSlash(p))
+}
+
+func filepathToSlash(p string) string {
+	return strings.ReplaceAll(p, "\\", "/")
+}
+
+func (m *MemFS) takeErr(op, p string) error {
+	k := op + ":" + m.key(p)
+	if err, ok := m.errs[k]; ok {
+		delete(m.errs, k)
+		return err
+	}
+	return nil
+}
+
+func (m *MemFS) Stat(p string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("Stat", p); err != nil {
+		return nil, err
+	}
+	k := m.key(p)
+	if data, ok := m.files[k]; ok {
+		return memFileInfo{name: path.Base(p), size: int64(len(data))}, nil
+	}
+	if m.dirs[k] {
+		return memFileInfo{name: path.Base(p), isDir: true}, nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: p, Err: os.ErrNotExist}
+}
+
+func (m *MemFS) ReadFile(p string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("ReadFile", p); err != nil {
+		return nil, err
+	}
+	data, ok := m.files[m.key(p)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrNotExist}
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	return out, nil
+}
+
+func (m *MemFS) WriteFile(p string, data []byte, _ os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("WriteFile", p); err != nil {
+		return err
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	m.files[m.key(p)] = out
+	return nil
+}
+
+func (m *MemFS) MkdirAll(p string, _ os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("MkdirAll", p); err != nil {
+		return err
+	}
+	m.dirs[m.key(p)] = true
+	return nil
+}
+
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("Rename", newpath); err != nil {
+		return err
+	}
+	data, ok := m.files[m.key(oldpath)]
+	if !ok {
+		return &os.PathError{Op: "rename", Path: oldpath, Err: os.ErrNotExist}
+	}
+	m.files[m.key(newpath)] = data
+	delete(m.files, m.key(oldpath))
+	return nil
+}
+
+func (m *MemFS) Remove(p string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.takeErr("Remove", p); err != nil {
+		return err
+	}
+	delete(m.files, m.key(p))
+	return nil
+}
+
+type memFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return 0600 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return i.isDir }
+func (i memFileInfo) Sys() any           { return nil }
+
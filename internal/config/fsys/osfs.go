@@ -0,0 +1,59 @@
+package fsys
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// osFS implements FS against the real filesystem.
+type osFS struct{}
+
+func (osFS) Stat(path string) (os.FileInfo, error) { return os.Stat(path) }
+
+func (osFS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+// WriteFile writes data to path and fsyncs it before returning, so a
+// caller building an atomic write (write to a temp path, WriteFile, then
+// Rename into place) gets a durable temp file rather than one that can be
+// lost to a crash before the rename.
+func (osFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (osFS) MkdirAll(path string, perm os.FileMode) error { return os.MkdirAll(path, perm) }
+
+// Rename renames oldpath to newpath and fsyncs the destination's parent
+// directory, so the rename itself is durable on POSIX filesystems.
+func (osFS) Rename(oldpath, newpath string) error {
+	if err := os.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(newpath))
+	return nil
+}
+
+func (osFS) Remove(path string) error { return os.Remove(path) }
+
+// syncDir fsyncs dir so a rename into it is durable. Best-effort: some
+// filesystems/platforms don't support fsync on a directory handle, so
+// errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
+}
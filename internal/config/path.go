@@ -0,0 +1,18 @@
+package config
+
+import "github.com/steveyegge/gastown/internal/pathutil"
+
+// CanonicalizePath resolves path to a canonical absolute form suitable for
+// deduplication. It delegates to pathutil.CanonicalizePath, which also
+// backs agenttrust's own path comparisons -- agenttrust can't import this
+// package directly (config depends on agenttrust for trust-folder
+// providers), so the canonicalization logic itself lives in the shared
+// leaf package and both sides call through to it.
+func CanonicalizePath(path string) (string, error) {
+	return pathutil.CanonicalizePath(path)
+}
+
+// SamePath reports whether a and b refer to the same canonical path.
+func SamePath(a, b string) bool {
+	return pathutil.SamePath(a, b)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// The exhaustive CanonicalizePath/SamePath behavior (symlinks, ".."
+// segments, case-folding) is covered by internal/pathutil's own tests;
+// these just confirm the wrappers here actually delegate.
+func TestCanonicalizePath_Delegates(t *testing.T) {
+	base := t.TempDir()
+	got, err := CanonicalizePath(base)
+	if err != nil {
+		t.Fatalf("CanonicalizePath: %v", err)
+	}
+	if !filepath.IsAbs(got) {
+		t.Fatalf("expected an absolute path, got %q", got)
+	}
+}
+
+func TestSamePath_Delegates(t *testing.T) {
+	base := t.TempDir()
+	if !SamePath(base, base+string(filepath.Separator)) {
+		t.Fatalf("expected %q and trailing-separator variant to be the same path", base)
+	}
+}
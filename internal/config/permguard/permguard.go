@@ -0,0 +1,113 @@
+// Package permguard checks that the security-sensitive config files Gas
+// Town writes -- the Claude Code SessionStart hook, each agent's
+// trusted_folders list -- still have the restrictive permissions and
+// ownership they were written with. Those files are privilege-granting
+// (a trusted_folders entry lets an agent run unattended in a directory; a
+// SessionStart hook runs a command on every launch), so something widening
+// them after the fact -- a careless chmod, a backup tool restoring world-
+// readable defaults -- is worth flagging even though Gas Town itself always
+// writes them correctly.
+package permguard
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// MaxDirMode and MaxFileMode are the permission ceilings CheckDir and
+// CheckFile enforce, matching the mode every managed directory/file is
+// created with (see internal/config/atomicjson's 0700 MkdirAll and 0600
+// temp-file write, and internal/witness's control-dir handshake, which
+// established the 0700/0600 convention this package mirrors).
+const (
+	MaxDirMode  = os.FileMode(0700)
+	MaxFileMode = os.FileMode(0600)
+)
+
+// Violation describes a file or directory whose permissions or ownership
+// have been widened since Gas Town wrote it.
+type Violation struct {
+	Path   string
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Reason)
+}
+
+// CheckFile reports a *Violation if path exists and either its permission
+// bits are wider than MaxFileMode or it's no longer owned by the current
+// user. A missing path is not a violation -- there's nothing to have been
+// widened. Permission bits and ownership aren't meaningful on Windows, so
+// CheckFile is always a no-op there.
+func CheckFile(path string) error {
+	return check(path, MaxFileMode)
+}
+
+// CheckDir is CheckFile's counterpart for a directory, enforcing MaxDirMode.
+func CheckDir(path string) error {
+	return check(path, MaxDirMode)
+}
+
+func check(path string, maxMode os.FileMode) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("permguard: stat %s: %w", path, err)
+	}
+
+	if reason, isWidened := widened(info, maxMode); isWidened {
+		return &Violation{Path: path, Reason: reason}
+	}
+	return nil
+}
+
+// widened reports whether info's permission bits exceed maxMode or it's
+// owned by a uid other than the current process's, along with a
+// human-readable reason for whichever (or both) is true.
+func widened(info os.FileInfo, maxMode os.FileMode) (string, bool) {
+	var reasons []string
+
+	if info.Mode().Perm()&^maxMode != 0 {
+		reasons = append(reasons, fmt.Sprintf("mode %04o is wider than %04o", info.Mode().Perm(), maxMode))
+	}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if uid := int(stat.Uid); uid != os.Getuid() {
+			reasons = append(reasons, fmt.Sprintf("owned by uid %d, not the current user", uid))
+		}
+	}
+
+	if len(reasons) == 0 {
+		return "", false
+	}
+	reason := reasons[0]
+	for _, r := range reasons[1:] {
+		reason += "; " + r
+	}
+	return reason, true
+}
+
+// Warn takes the result of CheckFile or CheckDir and, if it is a
+// *Violation, logs a warning to stderr instead of returning the error --
+// the non-strict default, so a widened file degrades to a visible warning
+// rather than blocking whatever operation is reading it.
+func Warn(err error) error {
+	if err == nil {
+		return nil
+	}
+	violation, ok := err.(*Violation)
+	if !ok {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "gastown: warning: %s\n", violation)
+	return nil
+}
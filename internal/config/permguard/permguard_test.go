@@ -0,0 +1,81 @@
+package permguard
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckFile_MissingIsNotAViolation(t *testing.T) {
+	if err := CheckFile(filepath.Join(t.TempDir(), "does-not-exist")); err != nil {
+		t.Errorf("CheckFile on a missing file: %v", err)
+	}
+}
+
+func TestCheckFile_CorrectModePasses(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := CheckFile(path); err != nil {
+		t.Errorf("CheckFile on a 0600 file: %v", err)
+	}
+}
+
+func TestCheckFile_WidenedModeIsAViolation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	err := CheckFile(path)
+	if err == nil {
+		t.Fatal("expected a violation for a 0644 file")
+	}
+	violation, ok := err.(*Violation)
+	if !ok {
+		t.Fatalf("expected a *Violation, got %T: %v", err, err)
+	}
+	if violation.Path != path {
+		t.Errorf("violation.Path = %q, want %q", violation.Path, path)
+	}
+}
+
+func TestCheckDir_WidenedModeIsAViolation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	dir := filepath.Join(t.TempDir(), "claude")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	if err := CheckDir(dir); err == nil {
+		t.Error("expected a violation for a 0755 directory")
+	}
+}
+
+func TestWarn_DowngradesViolationToNil(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits aren't meaningful on windows")
+	}
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := Warn(CheckFile(path)); err != nil {
+		t.Errorf("Warn should swallow a *Violation, got %v", err)
+	}
+}
+
+func TestWarn_PassesThroughOtherErrors(t *testing.T) {
+	other := os.ErrInvalid
+	if err := Warn(other); err != other {
+		t.Errorf("Warn(%v) = %v, want the original error unchanged", other, err)
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/agenttrust"
+)
+
+// TrustConfig describes a request to mark a working directory as trusted
+// with whichever agent is resolved for the role/rig/override combination.
+// It is the generalized successor to CopilotTrustConfig: the same fields
+// now drive any registered TrustProvider, not just Copilot.
+type TrustConfig = CopilotTrustConfig
+
+// TrustProvider knows how to mark a directory as trusted for one agent's
+// on-disk config format. Every built-in provider is a thin adapter over an
+// agenttrust.TrustStore (see agentTrustProvider below); register a store
+// for a new agent with agenttrust.Register and it picks up a TrustProvider
+// automatically.
+type TrustProvider interface {
+	// Name is the agent name this provider handles, e.g. "copilot".
+	Name() string
+	// EnsureTrusted marks trustPath as trusted for cfg, creating or updating
+	// the provider's config file as needed. It reports whether the file was
+	// changed.
+	EnsureTrusted(trustPath string, cfg TrustConfig) (bool, error)
+	// IsTrusted reports whether trustPath is already present in this
+	// provider's trust config, without writing anything. Used by
+	// diagnostics (see IsPathTrusted) to confirm a prior EnsureTrusted
+	// call actually landed.
+	IsTrusted(trustPath string, cfg TrustConfig) (bool, error)
+}
+
+var trustProviders = map[string]TrustProvider{}
+
+// RegisterTrustProvider adds (or replaces) the provider for its Name().
+// Built-in providers register themselves via init().
+func RegisterTrustProvider(p TrustProvider) {
+	trustProviders[strings.ToLower(p.Name())] = p
+}
+
+func init() {
+	for _, agent := range agenttrust.RegisteredAgents() {
+		RegisterTrustProvider(agentTrustProvider{agent: agent})
+	}
+}
+
+// EnsureTrustedFolder ensures the session's working directory is trusted by
+// whichever agent is resolved for cfg (respecting RoleAgents/AgentOverride).
+// Agents without a registered TrustProvider are silently skipped, matching
+// the historical Copilot-only behavior for those agents.
+func EnsureTrustedFolder(cfg TrustConfig) (bool, error) {
+	if cfg.WorkDir == "" {
+		return false, nil
+	}
+
+	rc, err := resolveRuntimeForCopilot(cfg)
+	if err != nil {
+		return false, err
+	}
+	if rc == nil {
+		return false, nil
+	}
+
+	provider, ok := lookupTrustProvider(rc)
+	if !ok {
+		return false, nil
+	}
+
+	trustPath := trustPathForRole(cfg)
+	updated, err := provider.EnsureTrusted(trustPath, cfg)
+	if err != nil {
+		return false, fmt.Errorf("updating %s trusted folders: %w", provider.Name(), err)
+	}
+	return updated, nil
+}
+
+// IsPathTrusted reports whether cfg's working directory (after applying the
+// same polecat-parent-dir special case as EnsureTrustedFolder) is already
+// present in the resolved agent's trust config. It never writes anything,
+// so it's safe to call from read-only diagnostics like speckit's doctor
+// subsystem. An agent with no registered TrustProvider reports trusted=true
+// since there's nothing to flag as missing.
+func IsPathTrusted(cfg TrustConfig) (bool, error) {
+	if cfg.WorkDir == "" {
+		return false, nil
+	}
+
+	rc, err := resolveRuntimeForCopilot(cfg)
+	if err != nil {
+		return false, err
+	}
+	if rc == nil {
+		return true, nil
+	}
+
+	provider, ok := lookupTrustProvider(rc)
+	if !ok {
+		return true, nil
+	}
+
+	trustPath := trustPathForRole(cfg)
+	return provider.IsTrusted(trustPath, cfg)
+}
+
+// ResolveRuntimeConfig resolves the agent a role/rig/override combination
+// would actually use, without touching any trust or hook state. It exposes
+// the same resolution EnsureTrustedFolder uses internally so read-only
+// diagnostics (see speckit.Doctor) can inspect rc.Hooks and rc.Provider
+// directly instead of re-deriving them.
+func ResolveRuntimeConfig(cfg TrustConfig) (*RuntimeConfig, error) {
+	return resolveRuntimeForCopilot(cfg)
+}
+
+// EnsureCopilotTrustedFolder ensures the agent resolved for cfg (not only
+// Copilot) trusts the session's working directory. For polecats, trusts the
+// polecats parent directory to cover all worktrees.
+//
+// Deprecated: prefer EnsureTrustedFolder, which dispatches to the resolved
+// agent's TrustProvider instead of hardcoding Copilot. This wrapper is kept
+// because callers rely on its error-only signature.
+func EnsureCopilotTrustedFolder(cfg CopilotTrustConfig) error {
+	_, err := EnsureTrustedFolder(cfg)
+	return err
+}
+
+// trustPathForRole applies the polecat-parent-dir special case uniformly
+// across all providers: polecats trust the polecats/ directory as a whole
+// instead of their individual worktrees.
+func trustPathForRole(cfg TrustConfig) string {
+	trustPath := cfg.WorkDir
+	if cfg.Role == "polecat" && cfg.RigPath != "" {
+		polecatsDir := filepath.Join(cfg.RigPath, "polecats")
+		workCanon, errW := CanonicalizePath(cfg.WorkDir)
+		polecatsCanon, errP := CanonicalizePath(polecatsDir)
+		if errW == nil && errP == nil && strings.HasPrefix(workCanon, polecatsCanon) {
+			trustPath = polecatsDir
+		} else if errW != nil || errP != nil {
+			// One of the paths doesn't exist yet; fall back to the
+			// pre-canonicalization prefix check rather than failing closed.
+			if strings.HasPrefix(filepath.Clean(cfg.WorkDir), filepath.Clean(polecatsDir)) {
+				trustPath = polecatsDir
+			}
+		}
+	}
+	return trustPath
+}
+
+func lookupTrustProvider(rc *RuntimeConfig) (TrustProvider, bool) {
+	name := strings.ToLower(rc.Provider)
+	if name == "" && rc.Command != "" {
+		name = strings.ToLower(filepath.Base(rc.Command))
+	}
+	if p, ok := trustProviders[name]; ok {
+		return p, true
+	}
+	if agenttrust.Registered(name) {
+		return agentTrustProvider{agent: name}, true
+	}
+	return nil, false
+}
+
+// agentTrustProvider adapts an agenttrust.TrustStore -- resolved fresh on
+// every call from cfg.ConfigDir, since a store is cheap to build and
+// ConfigDir varies per call in tests -- to the TrustProvider interface the
+// rest of this package expects.
+type agentTrustProvider struct {
+	agent string
+}
+
+func (p agentTrustProvider) Name() string { return p.agent }
+
+func (p agentTrustProvider) EnsureTrusted(trustPath string, cfg TrustConfig) (bool, error) {
+	store, err := agenttrust.NewStore(p.agent, cfg.ConfigDir)
+	if err != nil {
+		return false, err
+	}
+	return store.Ensure(trustPath, cfg.Role)
+}
+
+func (p agentTrustProvider) IsTrusted(trustPath string, cfg TrustConfig) (bool, error) {
+	store, err := agenttrust.NewStore(p.agent, cfg.ConfigDir)
+	if err != nil {
+		return false, err
+	}
+	entries, err := store.Load()
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if SamePath(entry, trustPath) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
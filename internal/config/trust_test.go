@@ -0,0 +1,246 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnsureTrustedFolder_ClaudeProvider(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+	workDir := filepath.Join(rigPath, "witness", "rig")
+
+	townSettings := NewTownSettings()
+	townSettings.DefaultAgent = "claude"
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	claudeHome := t.TempDir()
+
+	updated, err := EnsureTrustedFolder(TrustConfig{
+		TownRoot:  townRoot,
+		RigPath:   rigPath,
+		WorkDir:   workDir,
+		ConfigDir: claudeHome,
+	})
+	if err != nil {
+		t.Fatalf("EnsureTrustedFolder: %v", err)
+	}
+	if !updated {
+		t.Fatal("expected settings.json to be updated on first call")
+	}
+
+	data, err := os.ReadFile(filepath.Join(claudeHome, "settings.json"))
+	if err != nil {
+		t.Fatalf("ReadFile settings.json: %v", err)
+	}
+
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal settings.json: %v", err)
+	}
+	permissions, _ := cfg["permissions"].(map[string]any)
+	dirs, ok := permissions["additionalDirectories"].([]any)
+	if !ok || len(dirs) != 1 {
+		t.Fatalf("expected additionalDirectories to contain 1 entry, got: %v", dirs)
+	}
+	entry, _ := dirs[0].(map[string]any)
+	if entry["path"] != workDir {
+		t.Fatalf("expected additionalDirectories to contain %q, got: %v", workDir, dirs)
+	}
+
+	// Second call with the same path should be a no-op.
+	updated, err = EnsureTrustedFolder(TrustConfig{
+		TownRoot:  townRoot,
+		RigPath:   rigPath,
+		WorkDir:   workDir,
+		ConfigDir: claudeHome,
+	})
+	if err != nil {
+		t.Fatalf("second EnsureTrustedFolder: %v", err)
+	}
+	if updated {
+		t.Fatal("expected second call to be a no-op")
+	}
+}
+
+func TestEnsureTrustedFolder_CursorProvider(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+	workDir := filepath.Join(rigPath, "witness", "rig")
+
+	townSettings := NewTownSettings()
+	townSettings.DefaultAgent = "cursor"
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	cursorHome := t.TempDir()
+
+	if _, err := EnsureTrustedFolder(TrustConfig{
+		TownRoot:  townRoot,
+		RigPath:   rigPath,
+		WorkDir:   workDir,
+		ConfigDir: cursorHome,
+	}); err != nil {
+		t.Fatalf("EnsureTrustedFolder: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cursorHome, "trusted_folders"))
+	if err != nil {
+		t.Fatalf("ReadFile trusted_folders: %v", err)
+	}
+
+	var dirs []string
+	if err := json.Unmarshal(data, &dirs); err != nil {
+		t.Fatalf("Unmarshal trusted_folders: %v", err)
+	}
+	if len(dirs) != 1 || dirs[0] != workDir {
+		t.Fatalf("expected trusted_folders to contain %q, got: %v", workDir, dirs)
+	}
+}
+
+func TestEnsureTrustedFolder_PolecatParentAppliesToAllProviders(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+	polecatsDir := filepath.Join(rigPath, "polecats")
+	workDir := filepath.Join(polecatsDir, "capable", "testrig")
+
+	townSettings := NewTownSettings()
+	townSettings.DefaultAgent = "claude"
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	claudeHome := t.TempDir()
+
+	if _, err := EnsureTrustedFolder(TrustConfig{
+		Role:      "polecat",
+		TownRoot:  townRoot,
+		RigPath:   rigPath,
+		WorkDir:   workDir,
+		ConfigDir: claudeHome,
+	}); err != nil {
+		t.Fatalf("EnsureTrustedFolder: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(claudeHome, "settings.json"))
+	if err != nil {
+		t.Fatalf("ReadFile settings.json: %v", err)
+	}
+
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal settings.json: %v", err)
+	}
+	permissions, _ := cfg["permissions"].(map[string]any)
+	dirs, ok := permissions["additionalDirectories"].([]any)
+	if !ok || len(dirs) != 1 {
+		t.Fatalf("expected additionalDirectories to contain 1 entry, got: %v", dirs)
+	}
+	entry, _ := dirs[0].(map[string]any)
+	if entry["path"] != polecatsDir || entry["added_by_role"] != "polecat" {
+		t.Fatalf("expected additionalDirectories to contain polecats dir %q added by polecat, got: %v", polecatsDir, dirs)
+	}
+}
+
+func TestEnsureTrustedFolder_NewAgentStores(t *testing.T) {
+	for _, agent := range []string{"windsurf", "codex", "auggie"} {
+		t.Run(agent, func(t *testing.T) {
+			townRoot := t.TempDir()
+			rigPath := filepath.Join(townRoot, "testrig")
+			workDir := filepath.Join(rigPath, "witness", "rig")
+
+			townSettings := NewTownSettings()
+			townSettings.DefaultAgent = agent
+			if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+				t.Fatalf("SaveTownSettings: %v", err)
+			}
+			if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+				t.Fatalf("SaveRigSettings: %v", err)
+			}
+
+			configDir := t.TempDir()
+
+			updated, err := EnsureTrustedFolder(TrustConfig{
+				TownRoot:  townRoot,
+				RigPath:   rigPath,
+				WorkDir:   workDir,
+				ConfigDir: configDir,
+			})
+			if err != nil {
+				t.Fatalf("EnsureTrustedFolder: %v", err)
+			}
+			if !updated {
+				t.Fatal("expected trusted_folders to be updated on first call")
+			}
+
+			data, err := os.ReadFile(filepath.Join(configDir, "trusted_folders"))
+			if err != nil {
+				t.Fatalf("ReadFile trusted_folders: %v", err)
+			}
+			var dirs []string
+			if err := json.Unmarshal(data, &dirs); err != nil {
+				t.Fatalf("Unmarshal trusted_folders: %v", err)
+			}
+			if len(dirs) != 1 || dirs[0] != workDir {
+				t.Fatalf("expected trusted_folders to contain %q, got: %v", workDir, dirs)
+			}
+		})
+	}
+}
+
+func TestIsPathTrusted(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+	workDir := filepath.Join(rigPath, "witness", "rig")
+
+	townSettings := NewTownSettings()
+	townSettings.DefaultAgent = "claude"
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), NewRigSettings()); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	claudeHome := t.TempDir()
+	cfg := TrustConfig{
+		TownRoot:  townRoot,
+		RigPath:   rigPath,
+		WorkDir:   workDir,
+		ConfigDir: claudeHome,
+	}
+
+	trusted, err := IsPathTrusted(cfg)
+	if err != nil {
+		t.Fatalf("IsPathTrusted before EnsureTrustedFolder: %v", err)
+	}
+	if trusted {
+		t.Fatal("expected untrusted workDir to report trusted=false")
+	}
+
+	if _, err := EnsureTrustedFolder(cfg); err != nil {
+		t.Fatalf("EnsureTrustedFolder: %v", err)
+	}
+
+	trusted, err = IsPathTrusted(cfg)
+	if err != nil {
+		t.Fatalf("IsPathTrusted after EnsureTrustedFolder: %v", err)
+	}
+	if !trusted {
+		t.Fatal("expected workDir to report trusted=true after EnsureTrustedFolder")
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/steveyegge/gastown/internal/claude"
+	"github.com/steveyegge/gastown/internal/managedmeta"
 	"github.com/steveyegge/gastown/internal/util"
 )
 
@@ -61,6 +63,38 @@ func EnsureHooksForRole(workDir, role, hooksDir, hooksFile string) error {
 	return nil
 }
 
+// ManagedHookIDs returns the IDs of every Gas Town-managed entry present in
+// the hooks.json at workDir/hooksDir/hooksFile, sorted for stable output. A
+// missing file reports no IDs rather than an error. It's read-only and
+// intended for diagnostics (see speckit's doctor subsystem) that want to
+// notice an entry left over from a role the directory no longer represents
+// (e.g. "mail-inject" lingering after a role moved from autonomous to
+// interactive) without re-deriving mergeHooks' merge logic.
+func ManagedHookIDs(workDir, hooksDir, hooksFile string) ([]string, error) {
+	if hooksFile == "" {
+		return nil, nil
+	}
+	if hooksDir == "" {
+		hooksDir = "."
+	}
+
+	cfg, err := readHooksConfig(filepath.Join(workDir, hooksDir, hooksFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entries := range cfg.Hooks {
+		for _, entry := range entries {
+			if meta, ok := managedmeta.Of(entry); ok && meta.Owner == managedmeta.GastownOwner {
+				ids = append(ids, meta.ID)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
 func requiredHooksForRole(role string) (*hooksConfig, error) {
 	roleType := claude.RoleTypeFor(role)
 	templatePath := "config/hooks-interactive.json"
@@ -81,9 +115,37 @@ func requiredHooksForRole(role string) (*hooksConfig, error) {
 	if cfg.Hooks == nil {
 		cfg.Hooks = make(map[string][]map[string]any)
 	}
+	tagManagedEntries(&cfg)
 	return &cfg, nil
 }
 
+// tagManagedEntries stamps every entry in cfg with managed metadata so
+// mergeHooks can tell a Gas Town-owned hook from a user's own, and can tell
+// which version of a given hook it's looking at. The template itself
+// carries no IDs: an entry matching a signature in legacySignaturesByHook
+// takes that signature's id (e.g. "mail-inject"), and every other entry
+// gets a positional id (hookName + index).
+func tagManagedEntries(cfg *hooksConfig) {
+	for hookName, entries := range cfg.Hooks {
+		tagged := make([]map[string]any, len(entries))
+		for i, entry := range entries {
+			id := fmt.Sprintf("%s-%d", hookName, i)
+			for _, sig := range legacySignaturesByHook[hookName] {
+				if sig.matches(entry) {
+					id = sig.id
+					break
+				}
+			}
+			tagged[i] = managedmeta.With(entry, managedmeta.Meta{
+				Owner:         managedmeta.GastownOwner,
+				ID:            id,
+				SchemaVersion: cfg.Version,
+			})
+		}
+		cfg.Hooks[hookName] = tagged
+	}
+}
+
 func readHooksConfig(path string) (*hooksConfig, error) {
 	cfg := &hooksConfig{Version: 1, Hooks: make(map[string][]map[string]any)}
 	data, err := os.ReadFile(path)
@@ -108,16 +170,20 @@ func readHooksConfig(path string) (*hooksConfig, error) {
 	return cfg, nil
 }
 
+// mergeHooks folds required into existing in place, reporting whether it
+// changed anything. Each required entry carries managed metadata (owner,
+// id, schema_version): an existing managed entry with the same id is
+// replaced when the required schema_version is newer, a managed entry whose
+// id has dropped out of required is pruned, and a legacy (untagged) entry
+// matching a known migration signature is upgraded into managed form in
+// place. Entries with no Gas Town ownership tag are assumed to be the
+// user's own and are never touched.
 func mergeHooks(existing, required *hooksConfig) bool {
 	if existing == nil || required == nil {
 		return false
 	}
 
 	updated := false
-	if existing.Version == 0 && required.Version != 0 {
-		existing.Version = required.Version
-		updated = true
-	}
 	if required.Version > existing.Version {
 		existing.Version = required.Version
 		updated = true
@@ -127,18 +193,78 @@ func mergeHooks(existing, required *hooksConfig) bool {
 	}
 
 	for hookName, entries := range required.Hooks {
-		existingEntries := existing.Hooks[hookName]
-		for _, entry := range entries {
-			if !containsHookEntry(existingEntries, entry) {
-				existingEntries = append(existingEntries, entry)
+		merged, changed := mergeHookEntries(hookName, existing.Hooks[hookName], entries)
+		if changed {
+			updated = true
+		}
+		if len(merged) > 0 {
+			existing.Hooks[hookName] = merged
+		}
+	}
+	// Hook names the template no longer has at all still get their managed
+	// entries pruned.
+	for hookName, entries := range existing.Hooks {
+		if _, ok := required.Hooks[hookName]; ok {
+			continue
+		}
+		if merged, changed := mergeHookEntries(hookName, entries, nil); changed {
+			existing.Hooks[hookName] = merged
+			updated = true
+		}
+	}
+	return updated
+}
+
+// mergeHookEntries applies the managed-merge rules for a single hook name.
+func mergeHookEntries(hookName string, existingEntries, required []map[string]any) ([]map[string]any, bool) {
+	updated := false
+	requiredIDs := make(map[string]bool, len(required))
+
+	for _, entry := range required {
+		meta, _ := managedmeta.Of(entry)
+		requiredIDs[meta.ID] = true
+
+		if idx := findManagedByID(existingEntries, meta.ID); idx >= 0 {
+			existingMeta, _ := managedmeta.Of(existingEntries[idx])
+			if meta.SchemaVersion > existingMeta.SchemaVersion {
+				existingEntries[idx] = entry
 				updated = true
 			}
+			continue
 		}
-		if len(existingEntries) > 0 {
-			existing.Hooks[hookName] = existingEntries
+
+		if idx := findAnyLegacyMatch(existingEntries, legacySignaturesByHook[hookName]); idx >= 0 {
+			existingEntries[idx] = entry
+			updated = true
+			continue
+		}
+
+		if !containsHookEntry(existingEntries, entry) {
+			existingEntries = append(existingEntries, entry)
+			updated = true
 		}
 	}
-	return updated
+
+	pruned := existingEntries[:0]
+	for _, entry := range existingEntries {
+		if meta, ok := managedmeta.Of(entry); ok && meta.Owner == managedmeta.GastownOwner && !requiredIDs[meta.ID] {
+			updated = true
+			continue
+		}
+		pruned = append(pruned, entry)
+	}
+	return pruned, updated
+}
+
+// findManagedByID returns the index of the Gas Town-managed entry carrying
+// id, or -1 if none does.
+func findManagedByID(entries []map[string]any, id string) int {
+	for i, entry := range entries {
+		if meta, ok := managedmeta.Of(entry); ok && meta.Owner == managedmeta.GastownOwner && meta.ID == id {
+			return i
+		}
+	}
+	return -1
 }
 
 func containsHookEntry(existing []map[string]any, entry map[string]any) bool {
@@ -0,0 +1,56 @@
+package copilot
+
+import (
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/managedmeta"
+)
+
+// legacySignature recognizes a pre-versioning hook entry (no managedmeta.Key)
+// as corresponding to a known managed ID, so the first merge after upgrading
+// can migrate it in place instead of leaving a duplicate stale copy
+// alongside the new managed one.
+type legacySignature struct {
+	id      string
+	matches func(entry map[string]any) bool
+}
+
+// legacySignaturesByHook is the migration table: for each hook name, the
+// command signatures of entries that predate the managed-hook scheme.
+// Add an entry here whenever a required hook's command changes shape in a
+// way that would otherwise orphan the old inline copy.
+var legacySignaturesByHook = map[string][]legacySignature{
+	"sessionStart": {
+		{
+			id: "mail-inject",
+			matches: func(entry map[string]any) bool {
+				return commandContains(entry, "mail check --inject")
+			},
+		},
+	},
+}
+
+func commandContains(entry map[string]any, substr string) bool {
+	for _, key := range []string{"bash", "command"} {
+		if s, ok := entry[key].(string); ok && strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// findAnyLegacyMatch returns the index of the first untagged entry in
+// entries matching any of sigs, or -1 if none matches.
+func findAnyLegacyMatch(entries []map[string]any, sigs []legacySignature) int {
+	for i, entry := range entries {
+		if _, tagged := managedmeta.Of(entry); tagged {
+			continue
+		}
+		for _, sig := range sigs {
+			if sig.matches(entry) {
+				return i
+			}
+		}
+	}
+	return -1
+}
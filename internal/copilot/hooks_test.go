@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/managedmeta"
 )
 
 func TestEnsureHooksForRole_MergesMissingHooks(t *testing.T) {
@@ -91,3 +93,121 @@ func TestEnsureHooksForRole_NoChangesWhenComplete(t *testing.T) {
 		t.Error("expected hooks.json to be unchanged when already complete")
 	}
 }
+
+func TestMergeHooks_ReplacesManagedEntryOnNewerSchemaVersion(t *testing.T) {
+	existing := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"preCompact": {
+				managedmeta.With(map[string]any{"type": "command", "bash": "gt prime --hook"}, managedmeta.Meta{
+					Owner: managedmeta.GastownOwner, ID: "preCompact-0", SchemaVersion: 1,
+				}),
+			},
+		},
+	}
+	required := &hooksConfig{
+		Version: 2,
+		Hooks: map[string][]map[string]any{
+			"preCompact": {
+				managedmeta.With(map[string]any{"type": "command", "bash": "gt prime --hook --v2"}, managedmeta.Meta{
+					Owner: managedmeta.GastownOwner, ID: "preCompact-0", SchemaVersion: 2,
+				}),
+			},
+		},
+	}
+
+	if !mergeHooks(existing, required) {
+		t.Fatal("expected mergeHooks to report a change")
+	}
+	entries := existing.Hooks["preCompact"]
+	if len(entries) != 1 {
+		t.Fatalf("expected the stale entry to be replaced in place, got %d entries", len(entries))
+	}
+	if entries[0]["bash"] != "gt prime --hook --v2" {
+		t.Errorf("expected the newer command to win, got %v", entries[0]["bash"])
+	}
+}
+
+func TestMergeHooks_PrunesManagedEntryDroppedFromTemplate(t *testing.T) {
+	existing := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"preCompact": {
+				managedmeta.With(map[string]any{"type": "command", "bash": "gt prime --hook"}, managedmeta.Meta{
+					Owner: managedmeta.GastownOwner, ID: "preCompact-0", SchemaVersion: 1,
+				}),
+			},
+		},
+	}
+	required := &hooksConfig{Version: 2, Hooks: map[string][]map[string]any{}}
+
+	if !mergeHooks(existing, required) {
+		t.Fatal("expected mergeHooks to report a change")
+	}
+	if len(existing.Hooks["preCompact"]) != 0 {
+		t.Errorf("expected the dropped hook to be pruned, got %v", existing.Hooks["preCompact"])
+	}
+}
+
+func TestMergeHooks_LeavesThirdPartyEntryUntouched(t *testing.T) {
+	existing := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"sessionStart": {
+				{"type": "command", "bash": "my-own-hook --verbose"},
+			},
+		},
+	}
+	required := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"sessionStart": {
+				managedmeta.With(map[string]any{"type": "command", "bash": "gt prime --hook"}, managedmeta.Meta{
+					Owner: managedmeta.GastownOwner, ID: "sessionStart-0", SchemaVersion: 1,
+				}),
+			},
+		},
+	}
+
+	mergeHooks(existing, required)
+	entries := existing.Hooks["sessionStart"]
+	if len(entries) != 2 {
+		t.Fatalf("expected both the user's entry and the managed entry to be present, got %d", len(entries))
+	}
+	if entries[0]["bash"] != "my-own-hook --verbose" {
+		t.Errorf("expected the user's entry to be left in place, got %v", entries[0])
+	}
+}
+
+func TestMergeHooks_MigratesLegacyInlineEntry(t *testing.T) {
+	existing := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"sessionStart": {
+				{"type": "command", "bash": "gt mail check --inject"},
+			},
+		},
+	}
+	required := &hooksConfig{
+		Version: 1,
+		Hooks: map[string][]map[string]any{
+			"sessionStart": {
+				managedmeta.With(map[string]any{"type": "command", "bash": "gt mail check --inject --format=json"}, managedmeta.Meta{
+					Owner: managedmeta.GastownOwner, ID: "sessionStart-0", SchemaVersion: 1,
+				}),
+			},
+		},
+	}
+
+	if !mergeHooks(existing, required) {
+		t.Fatal("expected mergeHooks to report a change")
+	}
+	entries := existing.Hooks["sessionStart"]
+	if len(entries) != 1 {
+		t.Fatalf("expected the legacy entry to be migrated in place, not duplicated, got %d entries", len(entries))
+	}
+	meta, ok := managedmeta.Of(entries[0])
+	if !ok || meta.Owner != managedmeta.GastownOwner {
+		t.Errorf("expected the migrated entry to carry managed metadata, got %+v", entries[0])
+	}
+}
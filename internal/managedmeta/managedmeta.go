@@ -0,0 +1,62 @@
+// Package managedmeta tags JSON hook entries with an ownership/versioning
+// stamp so a merge pass can tell a Gas Town-managed entry (safe to upgrade
+// or prune) apart from one a user added by hand (always left untouched).
+// internal/claude (.claude/settings.json) and internal/copilot
+// (hooks.json) each maintain their own managed-hook merge logic against a
+// different file shape, but both tag entries the same way, so the tagging
+// itself lives here rather than being kept in sync by hand in two places.
+package managedmeta
+
+// GastownOwner is the Owner value Gas Town stamps on every hook entry it
+// manages.
+const GastownOwner = "gastown"
+
+// Key is the reserved field a managed hook entry carries its Meta under.
+// It's deliberately namespaced so it can't collide with a real hook field
+// (type/command/bash/...).
+const Key = "_gastown_managed"
+
+// Meta is the ownership/versioning stamp With attaches to a managed hook
+// entry.
+type Meta struct {
+	Owner         string `json:"owner"`
+	ID            string `json:"id"`
+	SchemaVersion int    `json:"schema_version"`
+}
+
+// Of reads the managed metadata from entry, if any.
+func Of(entry map[string]any) (Meta, bool) {
+	raw, ok := entry[Key]
+	if !ok {
+		return Meta{}, false
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return Meta{}, false
+	}
+	meta := Meta{}
+	if owner, ok := m["owner"].(string); ok {
+		meta.Owner = owner
+	}
+	if id, ok := m["id"].(string); ok {
+		meta.ID = id
+	}
+	if v, ok := m["schema_version"].(float64); ok {
+		meta.SchemaVersion = int(v)
+	}
+	return meta, meta.Owner != ""
+}
+
+// With returns a copy of entry tagged with meta.
+func With(entry map[string]any, meta Meta) map[string]any {
+	tagged := make(map[string]any, len(entry)+1)
+	for k, v := range entry {
+		tagged[k] = v
+	}
+	tagged[Key] = map[string]any{
+		"owner":          meta.Owner,
+		"id":             meta.ID,
+		"schema_version": meta.SchemaVersion,
+	}
+	return tagged
+}
@@ -0,0 +1,50 @@
+package managedmeta
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOf_RoundTripsThroughWith(t *testing.T) {
+	tagged := With(map[string]any{"type": "command", "command": "echo hi"}, Meta{
+		Owner:         GastownOwner,
+		ID:            "session-start",
+		SchemaVersion: 2,
+	})
+
+	// Of only ever sees entries that have been through a JSON round trip
+	// (read back off disk), where numeric fields decode as float64 --
+	// round-trip through json.Marshal/Unmarshal here rather than handing
+	// With's output straight to Of, so the test matches real usage.
+	data, err := json.Marshal(tagged)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	meta, ok := Of(entry)
+	if !ok {
+		t.Fatal("expected Of to find the metadata With just attached")
+	}
+	if meta.Owner != GastownOwner || meta.ID != "session-start" || meta.SchemaVersion != 2 {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+	if entry["command"] != "echo hi" {
+		t.Fatalf("expected With to preserve the rest of entry, got %v", entry)
+	}
+}
+
+func TestOf_UntaggedEntryIsNotManaged(t *testing.T) {
+	if _, ok := Of(map[string]any{"type": "command", "command": "echo hi"}); ok {
+		t.Fatal("expected an untagged entry to report not-managed")
+	}
+}
+
+func TestOf_MalformedTagIsNotManaged(t *testing.T) {
+	if _, ok := Of(map[string]any{Key: "not-an-object"}); ok {
+		t.Fatal("expected a non-object managed tag to report not-managed")
+	}
+}
@@ -0,0 +1,73 @@
+// Package pathutil holds path-canonicalization helpers shared by config and
+// agenttrust. It has no internal dependencies of its own so that both
+// packages -- which would otherwise form an import cycle (config depends on
+// agenttrust for trust-folder providers) -- can import it directly.
+package pathutil
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CanonicalizePath resolves path to a canonical absolute form suitable for
+// deduplication: it cleans the path, resolves symlinks (falling back to a
+// plain Abs when the path doesn't exist yet), and applies the case-folding
+// a given OS's filesystem uses by default. This is what every trusted-folder
+// read, write, and comparison should go through instead of a bare
+// filepath.Abs + strings.EqualFold, which breaks on symlinked rig
+// directories, `..` segments, and mixed-case paths on case-insensitive
+// filesystems.
+func CanonicalizePath(path string) (string, error) {
+	cleaned := filepath.Clean(path)
+
+	resolved, err := filepath.EvalSymlinks(cleaned)
+	if err != nil {
+		// Path doesn't exist yet (common for a WorkDir that hasn't been
+		// created at call time) - fall back to a plain absolute path.
+		resolved, err = filepath.Abs(cleaned)
+		if err != nil {
+			return "", err
+		}
+	} else if !filepath.IsAbs(resolved) {
+		resolved, err = filepath.Abs(resolved)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	resolved = normalizeDriveLetter(resolved)
+
+	if foldsCase() {
+		resolved = strings.ToLower(resolved)
+	}
+
+	return resolved, nil
+}
+
+// SamePath reports whether a and b refer to the same canonical path.
+func SamePath(a, b string) bool {
+	ca, errA := CanonicalizePath(a)
+	cb, errB := CanonicalizePath(b)
+	if errA != nil || errB != nil {
+		// Fall back to best-effort comparison rather than failing closed.
+		return strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+	}
+	return ca == cb
+}
+
+// foldsCase reports whether the current OS's default filesystem is
+// case-insensitive (macOS and Windows); Linux filesystems are case-sensitive.
+func foldsCase() bool {
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// normalizeDriveLetter upper-cases a Windows drive letter (c:\foo -> C:\foo)
+// so that "c:\Work" and "C:\Work" canonicalize identically. It is a no-op on
+// non-Windows paths (e.g. there's no colon in position 1).
+func normalizeDriveLetter(path string) string {
+	if len(path) >= 2 && path[1] == ':' {
+		return strings.ToUpper(path[:1]) + path[1:]
+	}
+	return path
+}
@@ -0,0 +1,91 @@
+package pathutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCanonicalizePath_SymlinkedDir(t *testing.T) {
+	base := t.TempDir()
+	real := filepath.Join(base, "real-rig")
+	if err := os.MkdirAll(real, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	link := filepath.Join(base, "rig")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	got, err := CanonicalizePath(link)
+	if err != nil {
+		t.Fatalf("CanonicalizePath: %v", err)
+	}
+	want, err := CanonicalizePath(real)
+	if err != nil {
+		t.Fatalf("CanonicalizePath(real): %v", err)
+	}
+	if got != want {
+		t.Fatalf("CanonicalizePath(symlink) = %q, want %q", got, want)
+	}
+}
+
+func TestCanonicalizePath_DotDotSegments(t *testing.T) {
+	base := t.TempDir()
+	polecats := filepath.Join(base, "polecats")
+	foo := filepath.Join(polecats, "foo")
+	if err := os.MkdirAll(foo, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	messy := filepath.Join(polecats, "..", "polecats", "foo", "testrig")
+	got, err := CanonicalizePath(messy)
+	if err != nil {
+		t.Fatalf("CanonicalizePath: %v", err)
+	}
+	want, err := CanonicalizePath(filepath.Join(foo, "testrig"))
+	if err != nil {
+		t.Fatalf("CanonicalizePath(clean): %v", err)
+	}
+	if got != want {
+		t.Fatalf("CanonicalizePath(%q) = %q, want %q", messy, got, want)
+	}
+}
+
+func TestCanonicalizePath_MixedCaseOnCaseInsensitiveOS(t *testing.T) {
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		t.Skip("case-insensitive folding only applies on windows/darwin")
+	}
+	a, err := CanonicalizePath("/Some/Mixed/Case")
+	if err != nil {
+		t.Fatalf("CanonicalizePath: %v", err)
+	}
+	b, err := CanonicalizePath("/some/mixed/case")
+	if err != nil {
+		t.Fatalf("CanonicalizePath: %v", err)
+	}
+	if a != b {
+		t.Fatalf("expected mixed-case paths to canonicalize equally, got %q vs %q", a, b)
+	}
+}
+
+func TestCanonicalizePath_NonExistentWorkDir(t *testing.T) {
+	base := t.TempDir()
+	missing := filepath.Join(base, "does-not-exist-yet", "rig")
+
+	got, err := CanonicalizePath(missing)
+	if err != nil {
+		t.Fatalf("CanonicalizePath should fall back to Abs for a missing path: %v", err)
+	}
+	if !filepath.IsAbs(got) {
+		t.Fatalf("expected an absolute path, got %q", got)
+	}
+}
+
+func TestSamePath_TrailingSeparator(t *testing.T) {
+	base := t.TempDir()
+	if !SamePath(base, base+string(filepath.Separator)) {
+		t.Fatalf("expected %q and trailing-separator variant to be the same path", base)
+	}
+}
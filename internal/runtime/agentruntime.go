@@ -0,0 +1,243 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// AgentRuntime abstracts the agent-specific steps of launching and driving a
+// tmux-hosted agent session (witness, deacon, patrol, ...), so a role can be
+// run on Claude, Copilot, a plain shell command, or a test double without the
+// caller branching on provider name.
+type AgentRuntime interface {
+	// Name identifies this backend, e.g. "claude", "copilot", "shell", "mock".
+	Name() string
+	// EnsureSettings installs whatever hook/settings files this backend needs
+	// in workDir before the session starts.
+	EnsureSettings(workDir, role string, rc *config.RuntimeConfig) error
+	// BuildStartCommand returns the shell command used to launch the agent.
+	BuildStartCommand(role, rigName, townRoot, rigPath, initialPrompt string) (string, error)
+	// TrustFolder marks workDir as trusted for this backend, if applicable.
+	TrustFolder(role, townRoot, rigPath, workDir, configDir string) error
+	// WaitReady blocks (best-effort) until the agent's prompt is visible.
+	WaitReady(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig, timeout time.Duration)
+	// AcceptWarnings dismisses any startup warning dialogs the backend shows.
+	AcceptWarnings(t *tmux.Tmux, sessionID string)
+	// PropulsionNudge returns the nudge command used to kick off autonomous
+	// work once the session is ready, or "" if this backend doesn't use one.
+	PropulsionNudge(role, workDir string) string
+	// EnvVars returns extra environment variables this backend wants set in
+	// the session beyond the common Gas Town set.
+	EnvVars(role, rigName, townRoot string) map[string]string
+}
+
+// Factory constructs a fresh AgentRuntime instance.
+type Factory func() AgentRuntime
+
+var registry = map[string]Factory{}
+
+// Register adds (or replaces) the factory for the given backend name.
+// Built-in backends register themselves via init().
+func Register(name string, factory Factory) {
+	registry[strings.ToLower(name)] = factory
+}
+
+// Lookup returns the AgentRuntime registered under name, or (nil, false) if
+// no backend is registered under that name.
+func Lookup(name string) (AgentRuntime, bool) {
+	factory, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+// ResolveRuntime picks an AgentRuntime for a session. overrideName (e.g. from
+// a --runtime flag or RoleConfig.Runtime) wins if set, and is an error if
+// no backend is registered under that name; when it's empty, the backend
+// is inferred from rc the way the
+// package-level helpers (WaitForCopilotReady etc.) already do: Copilot if
+// rc looks like Copilot, Claude otherwise.
+func ResolveRuntime(overrideName string, rc *config.RuntimeConfig) (AgentRuntime, error) {
+	if overrideName != "" {
+		if rt, ok := Lookup(overrideName); ok {
+			return rt, nil
+		}
+		return nil, fmt.Errorf("unknown runtime %q", overrideName)
+	}
+	if isCopilotRuntime(rc) {
+		rt, _ := Lookup("copilot")
+		return rt, nil
+	}
+	rt, _ := Lookup("claude")
+	return rt, nil
+}
+
+func init() {
+	Register("claude", func() AgentRuntime { return claudeRuntime{} })
+	Register("copilot", func() AgentRuntime { return copilotRuntime{} })
+	Register("shell", func() AgentRuntime { return shellRuntime{} })
+	Register("mock", func() AgentRuntime { return &mockRuntime{} })
+}
+
+// claudeRuntime wraps the existing Claude-hooks code path.
+type claudeRuntime struct{}
+
+func (claudeRuntime) Name() string { return "claude" }
+
+func (claudeRuntime) EnsureSettings(workDir, role string, rc *config.RuntimeConfig) error {
+	return EnsureSettingsForRole(workDir, role, rc)
+}
+
+func (claudeRuntime) BuildStartCommand(role, rigName, townRoot, rigPath, initialPrompt string) (string, error) {
+	return config.BuildAgentStartupCommandWithAgentOverride(role, rigName, townRoot, rigPath, initialPrompt, "claude")
+}
+
+func (claudeRuntime) TrustFolder(role, townRoot, rigPath, workDir, configDir string) error {
+	return config.EnsureCopilotTrustedFolder(config.CopilotTrustConfig{
+		Role:          role,
+		TownRoot:      townRoot,
+		RigPath:       rigPath,
+		WorkDir:       workDir,
+		AgentOverride: "claude",
+		ConfigDir:     configDir,
+	})
+}
+
+func (claudeRuntime) WaitReady(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig, timeout time.Duration) {
+	if t == nil || sessionID == "" {
+		return
+	}
+	_ = t.WaitForRuntimeReady(sessionID, rc, timeout)
+}
+
+func (claudeRuntime) AcceptWarnings(t *tmux.Tmux, sessionID string) {
+	if t == nil || sessionID == "" {
+		return
+	}
+	_ = t.AcceptBypassPermissionsWarning(sessionID)
+}
+
+func (claudeRuntime) PropulsionNudge(role, workDir string) string {
+	return session.PropulsionNudgeForRole(role, workDir)
+}
+
+func (claudeRuntime) EnvVars(role, rigName, townRoot string) map[string]string {
+	return nil
+}
+
+// copilotRuntime wraps the existing Copilot-specific readiness/fallback logic.
+type copilotRuntime struct{}
+
+func (copilotRuntime) Name() string { return "copilot" }
+
+func (copilotRuntime) EnsureSettings(workDir, role string, rc *config.RuntimeConfig) error {
+	return EnsureSettingsForRole(workDir, role, rc)
+}
+
+func (copilotRuntime) BuildStartCommand(role, rigName, townRoot, rigPath, initialPrompt string) (string, error) {
+	return config.BuildAgentStartupCommandWithAgentOverride(role, rigName, townRoot, rigPath, initialPrompt, "copilot")
+}
+
+func (copilotRuntime) TrustFolder(role, townRoot, rigPath, workDir, configDir string) error {
+	return config.EnsureCopilotTrustedFolder(config.CopilotTrustConfig{
+		Role:          role,
+		TownRoot:      townRoot,
+		RigPath:       rigPath,
+		WorkDir:       workDir,
+		AgentOverride: "copilot",
+		ConfigDir:     configDir,
+	})
+}
+
+func (copilotRuntime) WaitReady(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig, timeout time.Duration) {
+	WaitForCopilotReady(t, sessionID, rc, timeout)
+}
+
+func (copilotRuntime) AcceptWarnings(t *tmux.Tmux, sessionID string) {
+	if t == nil || sessionID == "" {
+		return
+	}
+	_ = t.AcceptBypassPermissionsWarning(sessionID)
+}
+
+func (copilotRuntime) PropulsionNudge(role, workDir string) string {
+	return session.PropulsionNudgeForRole(role, workDir)
+}
+
+func (copilotRuntime) EnvVars(role, rigName, townRoot string) map[string]string {
+	return nil
+}
+
+// shellRuntime runs an arbitrary command supplied by the role config,
+// skipping all Claude/Copilot-specific hook wiring and readiness heuristics.
+type shellRuntime struct{}
+
+func (shellRuntime) Name() string { return "shell" }
+
+func (shellRuntime) EnsureSettings(workDir, role string, rc *config.RuntimeConfig) error {
+	return nil
+}
+
+func (shellRuntime) BuildStartCommand(role, rigName, townRoot, rigPath, initialPrompt string) (string, error) {
+	return "", fmt.Errorf("shell runtime requires RoleConfig.StartCommand; none was supplied for role %q", role)
+}
+
+func (shellRuntime) TrustFolder(role, townRoot, rigPath, workDir, configDir string) error {
+	return nil
+}
+
+func (shellRuntime) WaitReady(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig, timeout time.Duration) {
+}
+
+func (shellRuntime) AcceptWarnings(t *tmux.Tmux, sessionID string) {}
+
+func (shellRuntime) PropulsionNudge(role, workDir string) string { return "" }
+
+func (shellRuntime) EnvVars(role, rigName, townRoot string) map[string]string { return nil }
+
+// mockRuntime is a test double that records every call it receives instead
+// of touching tmux, the filesystem, or any agent process.
+type mockRuntime struct {
+	EnsureSettingsCalls int
+	StartCommand        string
+	TrustedFolders       []string
+	ReadyWaited          bool
+	WarningsAccepted     bool
+}
+
+func (*mockRuntime) Name() string { return "mock" }
+
+func (m *mockRuntime) EnsureSettings(workDir, role string, rc *config.RuntimeConfig) error {
+	m.EnsureSettingsCalls++
+	return nil
+}
+
+func (m *mockRuntime) BuildStartCommand(role, rigName, townRoot, rigPath, initialPrompt string) (string, error) {
+	if m.StartCommand == "" {
+		m.StartCommand = "true"
+	}
+	return m.StartCommand, nil
+}
+
+func (m *mockRuntime) TrustFolder(role, townRoot, rigPath, workDir, configDir string) error {
+	m.TrustedFolders = append(m.TrustedFolders, workDir)
+	return nil
+}
+
+func (m *mockRuntime) WaitReady(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig, timeout time.Duration) {
+	m.ReadyWaited = true
+}
+
+func (m *mockRuntime) AcceptWarnings(t *tmux.Tmux, sessionID string) {
+	m.WarningsAccepted = true
+}
+
+func (*mockRuntime) PropulsionNudge(role, workDir string) string { return "" }
+
+func (*mockRuntime) EnvVars(role, rigName, townRoot string) map[string]string { return nil }
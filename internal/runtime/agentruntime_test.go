@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestLookup_BuiltinBackends(t *testing.T) {
+	for _, name := range []string{"claude", "copilot", "shell", "mock"} {
+		rt, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("expected backend %q to be registered", name)
+		}
+		if rt.Name() != name {
+			t.Fatalf("Lookup(%q).Name() = %q", name, rt.Name())
+		}
+	}
+
+	if _, ok := Lookup("does-not-exist"); ok {
+		t.Fatal("expected unknown backend to be absent")
+	}
+}
+
+func TestResolveRuntime_OverrideWins(t *testing.T) {
+	rt, err := ResolveRuntime("mock", &config.RuntimeConfig{Provider: "copilot"})
+	if err != nil {
+		t.Fatalf("ResolveRuntime: %v", err)
+	}
+	if rt.Name() != "mock" {
+		t.Fatalf("expected override to win, got %q", rt.Name())
+	}
+}
+
+func TestResolveRuntime_UnknownOverrideErrors(t *testing.T) {
+	if _, err := ResolveRuntime("not-a-real-backend", nil); err == nil {
+		t.Fatal("expected error for unknown runtime override")
+	}
+}
+
+func TestResolveRuntime_InfersFromRuntimeConfig(t *testing.T) {
+	rt, err := ResolveRuntime("", &config.RuntimeConfig{Provider: "copilot"})
+	if err != nil {
+		t.Fatalf("ResolveRuntime: %v", err)
+	}
+	if rt.Name() != "copilot" {
+		t.Fatalf("expected copilot backend, got %q", rt.Name())
+	}
+
+	rt, err = ResolveRuntime("", &config.RuntimeConfig{Provider: "claude"})
+	if err != nil {
+		t.Fatalf("ResolveRuntime: %v", err)
+	}
+	if rt.Name() != "claude" {
+		t.Fatalf("expected claude backend, got %q", rt.Name())
+	}
+}
+
+func TestMockRuntime_RecordsCalls(t *testing.T) {
+	rt, _ := Lookup("mock")
+	mock := rt.(*mockRuntime)
+
+	if err := mock.EnsureSettings("/work", "witness", nil); err != nil {
+		t.Fatalf("EnsureSettings: %v", err)
+	}
+	if err := mock.TrustFolder("witness", "/town", "/town/rig", "/work", ""); err != nil {
+		t.Fatalf("TrustFolder: %v", err)
+	}
+	mock.WaitReady(nil, "sess", nil, 0)
+	mock.AcceptWarnings(nil, "sess")
+
+	if mock.EnsureSettingsCalls != 1 {
+		t.Fatalf("expected 1 EnsureSettings call, got %d", mock.EnsureSettingsCalls)
+	}
+	if len(mock.TrustedFolders) != 1 || mock.TrustedFolders[0] != "/work" {
+		t.Fatalf("expected TrustedFolders = [/work], got %v", mock.TrustedFolders)
+	}
+	if !mock.ReadyWaited || !mock.WarningsAccepted {
+		t.Fatal("expected WaitReady and AcceptWarnings to be recorded")
+	}
+}
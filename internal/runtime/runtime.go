@@ -84,7 +84,7 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 		case "claude", "opencode":
 			return nil
 		case "copilot":
-			if !hooksAvailable(rc) {
+			if !HooksAvailable(rc) {
 				break
 			}
 			return nil
@@ -141,7 +141,16 @@ func copilotReadyConfig(rc *config.RuntimeConfig) *config.RuntimeConfig {
 	}
 	return ready
 }
-func hooksAvailable(rc *config.RuntimeConfig) bool {
+
+// HooksAvailable reports whether rc's hook settings file is actually present
+// on disk. An absolute Dir/SettingsFile path is checked as-is; a relative one
+// is resolved against the current directory for Copilot and against $HOME
+// for every other provider (not against the workDir EnsureSettingsForRole
+// writes into). It's used both to decide whether the CLI fallback commands
+// are needed (see StartupFallbackCommands) and, by speckit's doctor
+// subsystem, to confirm a hook install actually landed rather than just
+// trusting that EnsureSettingsForRole was called.
+func HooksAvailable(rc *config.RuntimeConfig) bool {
 	if rc == nil || rc.Hooks == nil {
 		return false
 	}
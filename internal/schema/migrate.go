@@ -0,0 +1,71 @@
+package schema
+
+import "fmt"
+
+// CurrentTrustConfigVersion is the version this package writes for
+// copilot-trust-config and claude-settings trust sections. v1 stored
+// trusted_folders as a flat []string; v2 wraps each entry in an object
+// carrying when and by which role it was added, mirroring how this
+// module's other versioned state files get upgraded forward instead of
+// rewritten from scratch.
+const CurrentTrustConfigVersion = 2
+
+// TrustFolderEntry is one entry in a v2 trusted_folders/additionalDirectories
+// array.
+type TrustFolderEntry struct {
+	Path        string `json:"path"`
+	AddedAt     string `json:"added_at,omitempty"`
+	AddedByRole string `json:"added_by_role,omitempty"`
+}
+
+// DecodeTrustFolders normalizes a decoded trusted_folders/additionalDirectories
+// value to a list of entries, accepting both the legacy v1 shape (a plain
+// array of path strings) and the current v2 shape (an array of
+// {path, added_at, added_by_role} objects). Legacy string entries come back
+// with AddedAt and AddedByRole left empty, since that history was never
+// recorded.
+func DecodeTrustFolders(raw any) ([]TrustFolderEntry, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("schema: trusted_folders is not an array (got %T)", raw)
+	}
+
+	entries := make([]TrustFolderEntry, 0, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			entries = append(entries, TrustFolderEntry{Path: v})
+		case map[string]any:
+			path, _ := v["path"].(string)
+			if path == "" {
+				return nil, fmt.Errorf("schema: trusted_folders[%d] is missing a path", i)
+			}
+			addedAt, _ := v["added_at"].(string)
+			addedByRole, _ := v["added_by_role"].(string)
+			entries = append(entries, TrustFolderEntry{Path: path, AddedAt: addedAt, AddedByRole: addedByRole})
+		default:
+			return nil, fmt.Errorf("schema: trusted_folders[%d] is neither a string nor an object (got %T)", i, item)
+		}
+	}
+	return entries, nil
+}
+
+// EncodeTrustFolders converts entries back to the v2 JSON-native shape
+// (an array of path/added_at/added_by_role objects) for writing.
+func EncodeTrustFolders(entries []TrustFolderEntry) []any {
+	out := make([]any, len(entries))
+	for i, entry := range entries {
+		obj := map[string]any{"path": entry.Path}
+		if entry.AddedAt != "" {
+			obj["added_at"] = entry.AddedAt
+		}
+		if entry.AddedByRole != "" {
+			obj["added_by_role"] = entry.AddedByRole
+		}
+		out[i] = obj
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+// Package schema ships JSON Schemas for two of the config files this module
+// writes (Claude's settings.json and the Copilot trust config) and
+// validates JSON documents against them. It implements a deliberately small subset
+// of JSON Schema -- type, required, properties, items, enum -- rather than
+// pulling in a full validator like gojsonschema: enough to catch the
+// malformed-user-edit cases EnsureTrustedFolder and its kin actually need
+// to guard against, without adding a heavyweight dependency to a module
+// that otherwise vendors almost nothing (see internal/config/atomicjson,
+// whose only external dependency is gofrs/flock).
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Name identifies one of the config file shapes this package validates.
+type Name string
+
+// Built-in schema names, one per config file shape this package validates.
+const (
+	ClaudeSettings     Name = "claude-settings"
+	CopilotTrustConfig Name = "copilot-trust-config"
+)
+
+// ValidationError reports a single schema violation, pointing at the
+// offending value with an RFC 6901 JSON Pointer so callers can surface
+// exactly what's wrong instead of a bare "invalid config" message.
+type ValidationError struct {
+	Pointer string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Pointer == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[Name]*spec{}
+)
+
+// Validate checks data (a JSON document) against name's schema, returning
+// a *ValidationError describing the first violation found, or nil if data
+// conforms.
+func Validate(name Name, data []byte) error {
+	s, err := load(name)
+	if err != nil {
+		return err
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return &ValidationError{Message: "invalid JSON: " + err.Error()}
+	}
+	return s.validate("", v)
+}
+
+// load parses and caches name's embedded schema file.
+func load(name Name) (*spec, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if s, ok := cache[name]; ok {
+		return s, nil
+	}
+
+	data, err := schemaFS.ReadFile("schemas/" + string(name) + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("schema: no embedded schema named %q: %w", name, err)
+	}
+
+	var s spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("schema: parsing %q: %w", name, err)
+	}
+	cache[name] = &s
+	return &s, nil
+}
@@ -0,0 +1,97 @@
+package schema
+
+import "testing"
+
+func TestValidate_CopilotTrustConfig_Valid(t *testing.T) {
+	data := []byte(`{
+		"version": 2,
+		"trusted_folders": [
+			{"path": "/work/dir", "added_at": "2026-01-01T00:00:00Z", "added_by_role": "witness"}
+		]
+	}`)
+	if err := Validate(CopilotTrustConfig, data); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidate_CopilotTrustConfig_MissingVersion(t *testing.T) {
+	data := []byte(`{"trusted_folders": []}`)
+	err := Validate(CopilotTrustConfig, data)
+	if err == nil {
+		t.Fatal("expected an error for a missing version field")
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	if verr.Pointer != "" {
+		t.Errorf("expected the root pointer for a missing top-level field, got %q", verr.Pointer)
+	}
+}
+
+func TestValidate_CopilotTrustConfig_WrongEntryShape(t *testing.T) {
+	data := []byte(`{"version": 2, "trusted_folders": [{"added_at": "x"}]}`)
+	err := Validate(CopilotTrustConfig, data)
+	if err == nil {
+		t.Fatal("expected an error for a trust entry missing path")
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	if verr.Pointer != "/trusted_folders/0" {
+		t.Errorf("Pointer = %q, want /trusted_folders/0", verr.Pointer)
+	}
+}
+
+func TestValidate_UnknownSchema(t *testing.T) {
+	if err := Validate(Name("not-a-schema"), []byte(`{}`)); err == nil {
+		t.Fatal("expected an error for an unregistered schema name")
+	}
+}
+
+func TestDecodeTrustFolders_LegacyStrings(t *testing.T) {
+	var raw any = []any{"/a", "/b"}
+	entries, err := DecodeTrustFolders(raw)
+	if err != nil {
+		t.Fatalf("DecodeTrustFolders: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Path != "/a" || entries[1].Path != "/b" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if entries[0].AddedAt != "" || entries[0].AddedByRole != "" {
+		t.Errorf("expected no provenance for migrated legacy entries, got %+v", entries[0])
+	}
+}
+
+func TestDecodeTrustFolders_V2Objects(t *testing.T) {
+	var raw any = []any{
+		map[string]any{"path": "/a", "added_at": "2026-01-01T00:00:00Z", "added_by_role": "polecat"},
+	}
+	entries, err := DecodeTrustFolders(raw)
+	if err != nil {
+		t.Fatalf("DecodeTrustFolders: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "/a" || entries[0].AddedByRole != "polecat" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestTrustFolders_RoundTripMigration(t *testing.T) {
+	legacy := []any{"/work/dir"}
+	entries, err := DecodeTrustFolders(legacy)
+	if err != nil {
+		t.Fatalf("DecodeTrustFolders: %v", err)
+	}
+	entries[0].AddedAt = "2026-01-01T00:00:00Z"
+	entries[0].AddedByRole = "witness"
+
+	encoded := EncodeTrustFolders(entries)
+	roundTripped, err := DecodeTrustFolders(encoded)
+	if err != nil {
+		t.Fatalf("DecodeTrustFolders(round trip): %v", err)
+	}
+	if len(roundTripped) != 1 || roundTripped[0] != entries[0] {
+		t.Fatalf("round trip = %+v, want %+v", roundTripped, entries[0])
+	}
+}
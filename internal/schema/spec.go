@@ -0,0 +1,107 @@
+package schema
+
+import "fmt"
+
+// spec is the subset of JSON Schema this package understands: enough to
+// describe object shapes with required/optional properties, arrays of a
+// uniform item type, primitive types, and closed enums. Unknown schema
+// keywords (e.g. "$id", "title", "description") are accepted and ignored.
+type spec struct {
+	Type       string           `json:"type"`
+	Required   []string         `json:"required"`
+	Properties map[string]*spec `json:"properties"`
+	Items      *spec            `json:"items"`
+	Enum       []any            `json:"enum"`
+}
+
+func (s *spec) validate(pointer string, v any) error {
+	if len(s.Enum) > 0 {
+		if !containsValue(s.Enum, v) {
+			return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("value %v is not one of %v", v, s.Enum)}
+		}
+	}
+
+	if s.Type != "" {
+		if err := checkType(pointer, s.Type, v); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, _ := v.(map[string]any)
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+		for key, child := range s.Properties {
+			val, ok := obj[key]
+			if !ok {
+				continue
+			}
+			if err := child.validate(pointer+"/"+key, val); err != nil {
+				return err
+			}
+		}
+	case "array":
+		if s.Items == nil {
+			break
+		}
+		arr, _ := v.([]any)
+		for i, item := range arr {
+			if err := s.Items.validate(fmt.Sprintf("%s/%d", pointer, i), item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkType(pointer, want string, v any) error {
+	var got string
+	switch v.(type) {
+	case nil:
+		got = "null"
+	case bool:
+		got = "boolean"
+	case string:
+		got = "string"
+	case float64:
+		got = "number"
+	case map[string]any:
+		got = "object"
+	case []any:
+		got = "array"
+	default:
+		got = "unknown"
+	}
+
+	if want == "integer" {
+		if n, ok := v.(float64); ok && n == float64(int64(n)) {
+			return nil
+		}
+		if got == "number" {
+			return &ValidationError{Pointer: pointer, Message: "expected an integer, got a non-integer number"}
+		}
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("expected type integer, got %s", got)}
+	}
+
+	if got != want {
+		return &ValidationError{Pointer: pointer, Message: fmt.Sprintf("expected type %s, got %s", want, got)}
+	}
+	return nil
+}
+
+// containsValue reports whether v appears in enum. Both sides were decoded
+// by encoding/json into `any`, so equal JSON values (numbers as float64,
+// strings as string, etc.) compare equal directly.
+func containsValue(enum []any, v any) bool {
+	for _, candidate := range enum {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
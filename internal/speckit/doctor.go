@@ -0,0 +1,297 @@
+package speckit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/copilot"
+	"github.com/steveyegge/gastown/internal/runtime"
+)
+
+// Status summarizes an agent's on-disk configuration state, as reported by
+// Doctor.
+type Status string
+
+const (
+	// StatusOK means the config file (if this agent writes one) exists and
+	// Validate found nothing missing.
+	StatusOK Status = "ok"
+	// StatusMissing means this agent has no config file yet. That's not
+	// itself an error -- EnsureConfig just hasn't been run for it -- so it
+	// carries no Issues unless another check found something.
+	StatusMissing Status = "missing"
+	// StatusDrift means a config file exists but Validate found it's
+	// missing entries EnsureConfig would otherwise have written.
+	StatusDrift Status = "drift"
+)
+
+// IssueSeverity classifies how serious a Doctor finding is.
+type IssueSeverity string
+
+const (
+	// SeverityWarning flags something worth a human's attention but that
+	// doesn't block CI (e.g. a leftover hook for a role that's no longer
+	// autonomous).
+	SeverityWarning IssueSeverity = "warning"
+	// SeverityError flags something CI should gate on (e.g. a config file
+	// missing required entries, or a command that isn't runnable).
+	SeverityError IssueSeverity = "error"
+)
+
+// Issue is a single problem Doctor found.
+type Issue struct {
+	Severity IssueSeverity `json:"severity"`
+	Message  string        `json:"message"`
+}
+
+// AgentStatus is Doctor's verdict for one registered AgentType.
+type AgentStatus struct {
+	AgentType AgentType `json:"agentType"`
+	Path      string    `json:"path,omitempty"`
+	Status    Status    `json:"status"`
+	Issues    []Issue   `json:"issues,omitempty"`
+}
+
+// DoctorReport is the aggregate result of running Doctor: a Status and set
+// of Issues per registered AgentType, plus the cross-cutting checks that
+// don't belong to any single generator. CI jobs can gate on
+// DoctorReport.OK(); humans get DoctorReport.String().
+type DoctorReport struct {
+	Agents []AgentStatus `json:"agents"`
+	// GtPath is set if cfg.GtPath doesn't resolve to anything runnable.
+	GtPath *Issue `json:"gtPath,omitempty"`
+	// Hooks is set if the resolved agent's hook settings aren't actually on
+	// disk, or carry an entry for a role capability cfg no longer claims.
+	Hooks *Issue `json:"hooks,omitempty"`
+	// Trust is set if the resolved agent has a TrustProvider but hasn't
+	// actually recorded cfg.WorkDir (or its polecats parent) as trusted.
+	Trust *Issue `json:"trust,omitempty"`
+}
+
+// OK reports whether Doctor found nothing wrong anywhere in the report.
+func (r *DoctorReport) OK() bool {
+	if r.GtPath != nil || r.Hooks != nil || r.Trust != nil {
+		return false
+	}
+	for _, a := range r.Agents {
+		if len(a.Issues) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the report the way `gt speckit doctor` prints it to a
+// terminal: one line per agent, cross-cutting issues last.
+func (r *DoctorReport) String() string {
+	var b strings.Builder
+	for _, a := range r.Agents {
+		fmt.Fprintf(&b, "%-8s %-28s %s\n", a.AgentType, a.Path, a.Status)
+		for _, issue := range a.Issues {
+			fmt.Fprintf(&b, "  [%s] %s\n", issue.Severity, issue.Message)
+		}
+	}
+	for _, named := range []struct {
+		label string
+		issue *Issue
+	}{
+		{"gt path", r.GtPath},
+		{"hooks", r.Hooks},
+		{"trust", r.Trust},
+	} {
+		if named.issue != nil {
+			fmt.Fprintf(&b, "%-8s [%s] %s\n", named.label, named.issue.Severity, named.issue.Message)
+		}
+	}
+	return b.String()
+}
+
+// JSON marshals the report for CI jobs to gate on.
+func (r *DoctorReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Doctor is the read-only verification subsystem behind `gt speckit
+// doctor`: for every registered AgentType it diffs the on-disk config
+// against what Generate would produce (see Generator.Validate), then layers
+// on the checks no single generator can make on its own -- a stale GtPath,
+// MCP server commands not on PATH, hooks the resolved runtime never
+// actually installed, a managed hook entry left over from a role that's no
+// longer autonomous, and whether the resolved agent's TrustProvider has
+// recorded cfg.WorkDir as trusted. It never writes anything.
+func Doctor(cfg Config) (*DoctorReport, error) {
+	report := &DoctorReport{}
+
+	for _, at := range AllAgentTypes() {
+		status, err := doctorAgentStatus(at, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s: %w", at, err)
+		}
+		report.Agents = append(report.Agents, status)
+	}
+
+	report.GtPath = checkGtPath(cfg)
+	report.Hooks = checkHooks(cfg)
+	report.Trust = checkTrust(cfg)
+
+	return report, nil
+}
+
+func doctorAgentStatus(at AgentType, cfg Config) (AgentStatus, error) {
+	gen, err := GetGenerator(at)
+	if err != nil {
+		return AgentStatus{}, err
+	}
+
+	status := AgentStatus{AgentType: at, Path: gen.Path(), Status: StatusOK}
+	if status.Path != "" {
+		if _, err := os.Stat(filepath.Join(cfg.WorkDir, status.Path)); err != nil {
+			status.Status = StatusMissing
+		}
+	}
+
+	if status.Status != StatusMissing {
+		if err := gen.Validate(cfg); err != nil {
+			status.Status = StatusDrift
+			status.Issues = append(status.Issues, Issue{Severity: SeverityError, Message: err.Error()})
+		}
+	}
+
+	if at == AgentMCP {
+		status.Issues = append(status.Issues, checkMCPCommands(cfg)...)
+	}
+
+	return status, nil
+}
+
+// checkGtPath flags a GtPath that names a binary no longer reachable: an
+// absolute or slash-qualified path that doesn't exist, or a bare name
+// that's not on PATH. An empty GtPath defaults to "gt" at call time (see
+// templateData), so there's nothing to check.
+func checkGtPath(cfg Config) *Issue {
+	if cfg.GtPath == "" {
+		return nil
+	}
+	if filepath.IsAbs(cfg.GtPath) || strings.ContainsRune(cfg.GtPath, filepath.Separator) {
+		if _, err := os.Stat(cfg.GtPath); err != nil {
+			return &Issue{Severity: SeverityError, Message: fmt.Sprintf("GtPath %q does not exist", cfg.GtPath)}
+		}
+		return nil
+	}
+	if _, err := exec.LookPath(cfg.GtPath); err != nil {
+		return &Issue{Severity: SeverityError, Message: fmt.Sprintf("GtPath %q is not on PATH", cfg.GtPath)}
+	}
+	return nil
+}
+
+// checkMCPCommands reads the MCP generator's servers.json (if any) and
+// flags any server whose Command isn't runnable, the same way checkGtPath
+// does for cfg.GtPath.
+func checkMCPCommands(cfg Config) []Issue {
+	path := filepath.Join(cfg.WorkDir, (&MCPGenerator{}).Path())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil // a missing file is already reflected in Status.
+	}
+
+	var mcpCfg MCPServersConfig
+	if err := json.Unmarshal(data, &mcpCfg); err != nil {
+		return []Issue{{Severity: SeverityError, Message: fmt.Sprintf("parsing %s: %v", path, err)}}
+	}
+
+	var issues []Issue
+	for name, entry := range mcpCfg.MCPServers {
+		if entry.Command == "" {
+			continue
+		}
+		if filepath.IsAbs(entry.Command) || strings.ContainsRune(entry.Command, filepath.Separator) {
+			if _, err := os.Stat(entry.Command); err != nil {
+				issues = append(issues, Issue{Severity: SeverityError, Message: fmt.Sprintf("MCP server %q command %q does not exist", name, entry.Command)})
+			}
+			continue
+		}
+		if _, err := exec.LookPath(entry.Command); err != nil {
+			issues = append(issues, Issue{Severity: SeverityError, Message: fmt.Sprintf("MCP server %q command %q is not on PATH", name, entry.Command)})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Message < issues[j].Message })
+	return issues
+}
+
+// checkHooks resolves the agent cfg's role/rig/override combination would
+// actually use and cross-checks runtime.HooksAvailable against it -- for
+// every provider with a Hooks config, not just Copilot -- then, for
+// Copilot specifically, flags a managed hook entry left over from a role
+// that's no longer autonomous (see copilot.ManagedHookIDs).
+func checkHooks(cfg Config) *Issue {
+	rc, err := config.ResolveRuntimeConfig(doctorTrustConfig(cfg))
+	if err != nil {
+		return &Issue{Severity: SeverityError, Message: fmt.Sprintf("resolving runtime config: %v", err)}
+	}
+	if rc == nil || rc.Hooks == nil {
+		return nil
+	}
+
+	if !runtime.HooksAvailable(rc) {
+		return &Issue{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s hooks are not installed at %s", rc.Hooks.Provider, filepath.Join(rc.Hooks.Dir, rc.Hooks.SettingsFile)),
+		}
+	}
+
+	if !strings.EqualFold(rc.Hooks.Provider, "copilot") {
+		return nil
+	}
+	ids, err := copilot.ManagedHookIDs(cfg.WorkDir, rc.Hooks.Dir, rc.Hooks.SettingsFile)
+	if err != nil {
+		return &Issue{Severity: SeverityError, Message: fmt.Sprintf("reading copilot hooks: %v", err)}
+	}
+	if cfg.RoleType != Autonomous {
+		for _, id := range ids {
+			if id == "mail-inject" {
+				return &Issue{
+					Severity: SeverityWarning,
+					Message:  "hooks.json still has the autonomous mail-inject hook, but this role is no longer autonomous",
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkTrust confirms the resolved agent's TrustProvider has actually
+// recorded cfg.WorkDir as trusted, the same way EnsureTrustedFolder would
+// leave it after a successful call.
+func checkTrust(cfg Config) *Issue {
+	trusted, err := config.IsPathTrusted(doctorTrustConfig(cfg))
+	if err != nil {
+		return &Issue{Severity: SeverityError, Message: fmt.Sprintf("checking trusted folders: %v", err)}
+	}
+	if !trusted {
+		return &Issue{
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s is not recorded as trusted yet; run EnsureTrustedFolder", cfg.WorkDir),
+		}
+	}
+	return nil
+}
+
+// doctorTrustConfig adapts a speckit.Config to the config.TrustConfig
+// EnsureTrustedFolder and ResolveRuntimeConfig expect. It leaves ConfigDir
+// and AgentOverride unset, matching how EnsureTrustedFolder is normally
+// called during a real session: those resolve to the per-user default
+// locations.
+func doctorTrustConfig(cfg Config) config.TrustConfig {
+	return config.TrustConfig{
+		Role:     cfg.Role,
+		TownRoot: cfg.TownRoot,
+		RigPath:  cfg.RigPath,
+		WorkDir:  cfg.WorkDir,
+	}
+}
@@ -0,0 +1,130 @@
+package speckit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDoctor_AllMissing(t *testing.T) {
+	workDir := t.TempDir()
+
+	report, err := Doctor(Config{
+		Role:     "crew",
+		RoleType: Interactive,
+		WorkDir:  workDir,
+	})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+
+	for _, a := range report.Agents {
+		if a.AgentType == AgentCLI {
+			continue // no file footprint, so it's never "missing".
+		}
+		if a.Status != StatusMissing {
+			t.Errorf("agent %s: Status = %v, want %v", a.AgentType, a.Status, StatusMissing)
+		}
+		if len(a.Issues) != 0 {
+			t.Errorf("agent %s: unexpected issues on a missing config: %v", a.AgentType, a.Issues)
+		}
+	}
+}
+
+func TestDoctor_DriftDetectedAfterGenerate(t *testing.T) {
+	workDir := t.TempDir()
+	cfg := Config{
+		Role:     "crew",
+		RoleType: Interactive,
+		WorkDir:  workDir,
+	}
+
+	if err := EnsureConfig(AgentCursor, cfg); err != nil {
+		t.Fatalf("EnsureConfig: %v", err)
+	}
+
+	// Strip the file back down to an empty object so Validate sees drift.
+	path := filepath.Join(workDir, (&CursorGenerator{}).Path())
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("truncating cursor config: %v", err)
+	}
+
+	report, err := Doctor(cfg)
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+
+	var cursor *AgentStatus
+	for i := range report.Agents {
+		if report.Agents[i].AgentType == AgentCursor {
+			cursor = &report.Agents[i]
+		}
+	}
+	if cursor == nil {
+		t.Fatal("no AgentCursor status in report")
+	}
+	if cursor.Status != StatusDrift {
+		t.Errorf("Status = %v, want %v", cursor.Status, StatusDrift)
+	}
+	if len(cursor.Issues) == 0 {
+		t.Error("expected at least one issue for a drifted config")
+	}
+	if report.OK() {
+		t.Error("DoctorReport.OK() = true, want false with a drifted agent")
+	}
+}
+
+func TestCheckGtPath(t *testing.T) {
+	gt, err := exec.LookPath("ls")
+	if err != nil {
+		t.Skip("ls not on PATH")
+	}
+
+	if issue := checkGtPath(Config{GtPath: gt}); issue != nil {
+		t.Errorf("checkGtPath(%q) = %v, want nil", gt, issue)
+	}
+	if issue := checkGtPath(Config{GtPath: ""}); issue != nil {
+		t.Errorf("checkGtPath(\"\") = %v, want nil", issue)
+	}
+	if issue := checkGtPath(Config{GtPath: "/no/such/gt-binary"}); issue == nil {
+		t.Error("checkGtPath(missing absolute path) = nil, want an Issue")
+	}
+	if issue := checkGtPath(Config{GtPath: "gt-binary-that-does-not-exist"}); issue == nil {
+		t.Error("checkGtPath(missing PATH entry) = nil, want an Issue")
+	}
+}
+
+func TestCheckMCPCommands(t *testing.T) {
+	workDir := t.TempDir()
+	mcpPath := filepath.Join(workDir, ".mcp", "servers.json")
+	if err := os.MkdirAll(filepath.Dir(mcpPath), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	content := `{"mcpServers":{"gastown":{"command":"gt-binary-that-does-not-exist"}}}`
+	if err := os.WriteFile(mcpPath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	issues := checkMCPCommands(Config{WorkDir: workDir})
+	if len(issues) != 1 {
+		t.Fatalf("checkMCPCommands: got %d issues, want 1: %v", len(issues), issues)
+	}
+}
+
+func TestDoctorReport_JSONRoundTrip(t *testing.T) {
+	report, err := Doctor(Config{Role: "crew", RoleType: Interactive, WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Doctor: %v", err)
+	}
+	data, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("JSON() returned empty output")
+	}
+	if s := report.String(); s == "" {
+		t.Error("String() returned empty output")
+	}
+}
@@ -1,17 +1,21 @@
 // Package speckit provides agent configuration generators for various LLM coding assistants.
-// It supports Claude, Copilot, Cursor, generic MCP servers, and CLI fallback configurations.
+// It supports Claude, Copilot, Cursor, Aider, Zed, Continue, generic MCP servers, and CLI
+// fallback configurations.
 package speckit
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"text/template"
 )
 
-//go:embed config/*.json config/*.md
+//go:embed config/*.json config/*.md config/*.yml
 var configFS embed.FS
 
 // AgentType identifies the type of agent to generate configuration for.
@@ -28,6 +32,12 @@ const (
 	AgentMCP AgentType = "mcp"
 	// AgentCLI generates CLI fallback commands
 	AgentCLI AgentType = "cli"
+	// AgentAider generates .aider.conf.yml
+	AgentAider AgentType = "aider"
+	// AgentZed generates .zed/settings.json (and .zed/tasks.json for autonomous roles)
+	AgentZed AgentType = "zed"
+	// AgentContinue generates .continue/config.json
+	AgentContinue AgentType = "continue"
 )
 
 // RoleType indicates whether a role is autonomous or interactive.
@@ -52,12 +62,817 @@ func RoleTypeFor(role string) RoleType {
 
 // Config holds the configuration for generating agent settings.
 type Config struct {
+	// Role is the role name (e.g. "witness", "mayor"), exposed to templates
+	// as {{.Role}}.
+	Role string
 	// WorkDir is the target directory for configuration files.
 	WorkDir string
 	// RoleType determines which template variant to use.
 	RoleType RoleType
 	// GtPath is the path to the gt binary (for fallback commands).
 	GtPath string
+	// TownRoot is the gastown town root, exposed to templates as {{.TownRoot}}.
+	TownRoot string
+	// RigPath is the path to the current rig, exposed to templates as {{.RigPath}}.
+	RigPath string
+	// SessionEnvVar is the env var name the agent uses for its session ID,
+	// exposed to templates as {{.SessionEnvVar}}.
+	SessionEnvVar string
+	// Vars carries caller-supplied template variables (e.g. custom MCP
+	// server entries) in addition to the built-ins above. Vars take
+	// precedence when a key collides with a built-in.
+	Vars map[string]string
+	// MergePolicy controls how a generator behaves when its config file
+	// already exists. Zero value is SkipIfExists, matching the historical
+	// behavior.
+	MergePolicy MergePolicy
+	// DryRun makes EnsureConfig/EnsureAllConfigs call each generator's
+	// Plan instead of its Generate, so nothing is written to disk.
+	DryRun bool
+}
+
+// MergePolicy selects how a generator reconciles its rendered template
+// against a config file that already exists on disk.
+type MergePolicy int
+
+const (
+	// SkipIfExists leaves an existing file completely untouched. This is
+	// the zero value, so callers that don't set MergePolicy keep the
+	// historical skip-if-exists behavior.
+	SkipIfExists MergePolicy = iota
+	// MergeMissing deep-merges the rendered template into the existing
+	// file, injecting only the keys and array entries gastown requires
+	// while preserving everything else the user has in place. The file is
+	// only rewritten if the merge actually changed something.
+	MergeMissing
+	// Overwrite always writes the rendered template, discarding whatever
+	// was there before.
+	Overwrite
+)
+
+// PlanAction describes what Generate would do to a generator's config file,
+// as reported by Generator.Plan without writing anything.
+type PlanAction int
+
+const (
+	// PlanSkip means the file already exists and SkipIfExists (or an
+	// already-complete MergeMissing) would leave it untouched.
+	PlanSkip PlanAction = iota
+	// PlanCreate means the file doesn't exist yet and would be written
+	// from scratch.
+	PlanCreate
+	// PlanMerge means the file exists and would be rewritten, either by
+	// merging in missing keys/entries or by an outright Overwrite.
+	PlanMerge
+)
+
+// String renders a as the word a `gt speckit ensure --dry-run` report
+// would show the user.
+func (a PlanAction) String() string {
+	switch a {
+	case PlanSkip:
+		return "skip"
+	case PlanCreate:
+		return "create"
+	case PlanMerge:
+		return "merge"
+	default:
+		return "unknown"
+	}
+}
+
+// PlanResult describes what Generate would do to one agent's config file,
+// without writing anything. Before is nil for a PlanCreate (there's nothing
+// to diff against yet) and After holds the content that would be written;
+// both are populated, and equal, for a PlanSkip.
+type PlanResult struct {
+	AgentType AgentType
+	Path      string
+	Action    PlanAction
+	Before    []byte
+	After     []byte
+}
+
+// SafeWriteOptions controls SafeWrite's permission and backup-rotation
+// behavior.
+type SafeWriteOptions struct {
+	// Perm is the file mode for the written file. Zero defaults to 0644.
+	// Ignored when Sensitive is set.
+	Perm os.FileMode
+	// Sensitive marks data as possibly carrying secrets (e.g. an MCP
+	// server's auth token), forcing 0600 regardless of Perm.
+	Sensitive bool
+	// KeepBackups is how many rotated path+".bak.N" backups to retain.
+	// Zero means the default of defaultKeepBackups.
+	KeepBackups int
+}
+
+// defaultKeepBackups is how many rotated backups SafeWrite keeps when
+// SafeWriteOptions.KeepBackups is left at its zero value.
+const defaultKeepBackups = 3
+
+// writeTempFile is the step SafeWrite uses to populate its temp file
+// before syncing and renaming it into place. It's a variable rather than
+// an inline f.Write call so tests can simulate a writer that crashes
+// partway through a write (e.g. a full disk or a killed process) without
+// needing to actually exhaust disk space.
+var writeTempFile = func(f *os.File, data []byte) error {
+	_, err := f.Write(data)
+	return err
+}
+
+// SafeWrite atomically replaces path with data. It writes to a sibling
+// temp file (path+".tmp-<pid>"), fsyncs it, rotates any file already at
+// path into path+".bak.1" (shifting older backups up one slot and
+// dropping whatever falls off the end of opts.KeepBackups), and only then
+// renames the temp file into place -- so a crash or a failing writer
+// midway through leaves the original file (or its most recent backup)
+// intact rather than a half-written config. See Rollback for undoing a
+// SafeWrite after the fact.
+func SafeWrite(path string, data []byte, opts SafeWriteOptions) error {
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	if opts.Sensitive {
+		perm = 0600
+	}
+	keep := opts.KeepBackups
+	if keep == 0 {
+		keep = defaultKeepBackups
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	tmpPath := fmt.Sprintf("%s.tmp-%d", path, os.Getpid())
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	if err := writeTempFile(tmp, data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("syncing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+
+	if err := rotateBackups(path, keep); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("renaming into place: %w", err)
+	}
+	return nil
+}
+
+// rotateBackups shifts any existing path+".bak.1" .. path+".bak.N-1" up by
+// one slot, drops whatever would fall off the end of keep, and copies
+// whatever is currently at path into path+".bak.1". A path that doesn't
+// exist yet is not an error -- there's nothing to back up.
+func rotateBackups(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading %s for backup: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	oldest := fmt.Sprintf("%s.bak.%d", path, keep)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing %s: %w", oldest, err)
+	}
+	for n := keep - 1; n >= 1; n-- {
+		from := fmt.Sprintf("%s.bak.%d", path, n)
+		to := fmt.Sprintf("%s.bak.%d", path, n+1)
+		if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating %s to %s: %w", from, to, err)
+		}
+	}
+
+	if err := os.WriteFile(fmt.Sprintf("%s.bak.1", path), data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	return nil
+}
+
+// Rollback restores path from its most recent rotated backup
+// (path+".bak.1"), undoing the last SafeWrite. It goes through SafeWrite
+// itself, so the file being rolled back becomes the new path+".bak.1" in
+// turn -- a bad rollback can itself be rolled back. Returns an error if
+// there's no backup to restore from.
+func Rollback(path string) error {
+	backup := fmt.Sprintf("%s.bak.1", path)
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no backup found for %s", path)
+		}
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	info, err := os.Stat(backup)
+	if err != nil {
+		return fmt.Errorf("stat backup: %w", err)
+	}
+	return SafeWrite(path, data, SafeWriteOptions{Perm: info.Mode().Perm()})
+}
+
+// RollbackAgent restores agentType's config file (at its PathResolver-
+// resolved path) from its most recent backup. This is the library call a
+// `gt speckit rollback <agent>` subcommand would make; gastown's CLI
+// entrypoint lives outside this package, so no command wiring is added
+// here.
+func RollbackAgent(agentType AgentType, cfg Config) error {
+	gen, err := GetGenerator(agentType)
+	if err != nil {
+		return err
+	}
+	path := gen.Path()
+	if path == "" {
+		return fmt.Errorf("agent type %s has no config file to roll back", agentType)
+	}
+	return Rollback(resolvedConfigPath(agentType, path, cfg))
+}
+
+// agentDirOverride describes how PathResolver resolves one AgentType's
+// config directory: the env vars checked (in order) before XDG_CONFIG_HOME,
+// the XDG subdirectory appended after XDG_CONFIG_HOME, and the
+// WorkDir-relative default used once no override applies. defaultDir is ""
+// for agents (Aider) whose config file lives directly in WorkDir rather
+// than a subdirectory of it.
+type agentDirOverride struct {
+	envVars    []string
+	xdgSubdir  string
+	defaultDir string
+}
+
+// agentDirOverrides holds the env var / XDG subdir / default directory for
+// every agent type that writes a config file. AgentCLI isn't here since it
+// writes nothing.
+var agentDirOverrides = map[AgentType]agentDirOverride{
+	AgentClaude:   {envVars: []string{"GASTOWN_CLAUDE_DIR", "CLAUDE_CONFIG_HOME"}, xdgSubdir: "claude", defaultDir: ".claude"},
+	AgentCopilot:  {envVars: []string{"GASTOWN_COPILOT_DIR"}, xdgSubdir: "copilot", defaultDir: ".github"},
+	AgentCursor:   {envVars: []string{"GASTOWN_CURSOR_DIR"}, xdgSubdir: "cursor", defaultDir: ".cursor"},
+	AgentMCP:      {envVars: []string{"GASTOWN_MCP_DIR"}, xdgSubdir: "gastown-mcp", defaultDir: ".mcp"},
+	AgentAider:    {envVars: []string{"GASTOWN_AIDER_DIR"}, xdgSubdir: "aider", defaultDir: ""},
+	AgentZed:      {envVars: []string{"GASTOWN_ZED_DIR"}, xdgSubdir: "zed", defaultDir: ".zed"},
+	AgentContinue: {envVars: []string{"GASTOWN_CONTINUE_DIR"}, xdgSubdir: "continue", defaultDir: ".continue"},
+}
+
+// PathResolver resolves the directory an agent's config file should live
+// in. It mirrors the ConfigDir/envVar/XDG_CONFIG_HOME precedence
+// agenttrust.resolvePath applies to Copilot and Gemini's global trust
+// stores, generalized per AgentType -- except the final fallback rung is
+// cfg.WorkDir rather than $HOME, since speckit's generators are
+// project-scoped, not global. The zero value is ready to use.
+type PathResolver struct{}
+
+// Resolve returns the directory agentType's config should be written to
+// for cfg: the first of agentType's env var overrides that's set, else
+// $XDG_CONFIG_HOME/<subdir> if XDG_CONFIG_HOME is set, else cfg.WorkDir
+// joined with the agent's usual relative directory (or cfg.WorkDir itself,
+// for an agent whose config file has no subdirectory). It does not create
+// the directory. Agent types with no override registered (AgentCLI)
+// resolve to cfg.WorkDir.
+func (PathResolver) Resolve(agentType AgentType, cfg Config) string {
+	override, ok := agentDirOverrides[agentType]
+	if !ok {
+		return cfg.WorkDir
+	}
+	for _, envVar := range override.envVars {
+		if v := os.Getenv(envVar); v != "" {
+			return v
+		}
+	}
+	if override.xdgSubdir != "" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			return filepath.Join(xdg, override.xdgSubdir)
+		}
+	}
+	if override.defaultDir == "" {
+		return cfg.WorkDir
+	}
+	return filepath.Join(cfg.WorkDir, override.defaultDir)
+}
+
+// resolvedConfigPath returns the full path agentType's config file would be
+// written to for cfg: PathResolver's resolved directory joined with
+// relPath's base name.
+func resolvedConfigPath(agentType AgentType, relPath string, cfg Config) string {
+	return filepath.Join(PathResolver{}.Resolve(agentType, cfg), filepath.Base(relPath))
+}
+
+// ResolvedPaths reports, for every registered agent type that writes a
+// config file, the full path it would be written to for cfg. This is the
+// library call a `gt speckit where` command would make to show users where
+// their configs actually live once GASTOWN_*_DIR/XDG_CONFIG_HOME overrides
+// are in play; gastown's CLI entrypoint lives outside this package, so no
+// command wiring is added here.
+func ResolvedPaths(cfg Config) (map[AgentType]string, error) {
+	paths := make(map[AgentType]string)
+	for _, agentType := range AllAgentTypes() {
+		gen, err := GetGenerator(agentType)
+		if err != nil {
+			return nil, err
+		}
+		relPath := gen.Path()
+		if relPath == "" {
+			continue
+		}
+		paths[agentType] = resolvedConfigPath(agentType, relPath, cfg)
+	}
+	return paths, nil
+}
+
+// mergeJSONGenerate is the shared Generate() body for JSON-based generators
+// (Claude, Cursor, MCP): it renders templateName, then applies cfg's
+// MergePolicy against whatever (if anything) already exists at configPath.
+func mergeJSONGenerate(configPath, templateName string, cfg Config, opts SafeWriteOptions) error {
+	plan, err := planJSONGenerate(configPath, templateName, cfg)
+	if err != nil {
+		return err
+	}
+	return writePlan(plan, opts)
+}
+
+// planJSONGenerate mirrors mergeJSONGenerate's decision logic without
+// writing anything, so both Generate and Plan can share it.
+func planJSONGenerate(configPath, templateName string, cfg Config) (PlanResult, error) {
+	rendered, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return PlanResult{}, err
+	}
+
+	if cfg.MergePolicy != MergeMissing {
+		return planWholeFile(configPath, rendered, cfg)
+	}
+
+	var required map[string]any
+	if err := json.Unmarshal(rendered, &required); err != nil {
+		return PlanResult{}, fmt.Errorf("parsing rendered template %s: %w", templateName, err)
+	}
+	return planJSONGenerateFromMap(configPath, required, cfg)
+}
+
+// mergeJSONGenerateFromMap is mergeJSONGenerate's tail end, factored out so
+// callers that need to assemble `required` themselves (ContinueGenerator's
+// systemMessage can't come from a static embedded template, since it embeds
+// CopilotGenerator's rendered text) can still share the same MergePolicy
+// handling.
+func mergeJSONGenerateFromMap(configPath string, required map[string]any, cfg Config, opts SafeWriteOptions) error {
+	plan, err := planJSONGenerateFromMap(configPath, required, cfg)
+	if err != nil {
+		return err
+	}
+	return writePlan(plan, opts)
+}
+
+// planJSONGenerateFromMap is planJSONGenerate's counterpart for callers
+// that assemble `required` themselves rather than parsing it out of a
+// rendered template.
+func planJSONGenerateFromMap(configPath string, required map[string]any, cfg Config) (PlanResult, error) {
+	if cfg.MergePolicy != MergeMissing {
+		data, err := json.MarshalIndent(required, "", "  ")
+		if err != nil {
+			return PlanResult{}, fmt.Errorf("encoding config: %w", err)
+		}
+		return planWholeFile(configPath, data, cfg)
+	}
+
+	before, _ := os.ReadFile(configPath)
+	existing, err := readJSONConfig(configPath)
+	if err != nil {
+		return PlanResult{}, err
+	}
+
+	if !mergeMissingKeys(existing, required) {
+		return PlanResult{Path: configPath, Action: PlanSkip, Before: before, After: before}, nil
+	}
+
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return PlanResult{}, fmt.Errorf("encoding config: %w", err)
+	}
+	action := PlanMerge
+	if len(before) == 0 {
+		action = PlanCreate
+	}
+	return PlanResult{Path: configPath, Action: action, Before: before, After: data}, nil
+}
+
+// planWholeFile builds the PlanResult for a generator that (per cfg's
+// MergePolicy) would write content as-is rather than merging piecemeal:
+// SkipIfExists leaves an existing file untouched, otherwise the file is
+// created (if it doesn't exist yet) or overwritten in full.
+func planWholeFile(configPath string, content []byte, cfg Config) (PlanResult, error) {
+	before, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return PlanResult{}, fmt.Errorf("reading config: %w", err)
+	}
+	existed := err == nil
+
+	if !existed {
+		return PlanResult{Path: configPath, Action: PlanCreate, After: content}, nil
+	}
+	if cfg.MergePolicy == SkipIfExists {
+		return PlanResult{Path: configPath, Action: PlanSkip, Before: before, After: before}, nil
+	}
+	return PlanResult{Path: configPath, Action: PlanMerge, Before: before, After: content}, nil
+}
+
+// writePlan applies plan to disk: a PlanSkip plan leaves the file alone,
+// anything else writes plan.After through SafeWrite.
+func writePlan(plan PlanResult, opts SafeWriteOptions) error {
+	if plan.Action == PlanSkip {
+		return nil
+	}
+	return SafeWrite(plan.Path, plan.After, opts)
+}
+
+// readJSONConfig reads and parses configPath, returning an empty map if the
+// file doesn't exist yet.
+func readJSONConfig(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return map[string]any{}, nil
+	}
+	var existing map[string]any
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	return existing, nil
+}
+
+// mergeMissingKeys deep-merges required into existing in place, reporting
+// whether it changed anything. Maps recurse key by key; arrays gain any
+// required entry not already present (by deep equality); every other key
+// the user already has, and every scalar value they've already set, is left
+// untouched.
+func mergeMissingKeys(existing, required map[string]any) bool {
+	updated := false
+	for key, reqVal := range required {
+		curVal, present := existing[key]
+		if !present {
+			existing[key] = reqVal
+			updated = true
+			continue
+		}
+
+		switch reqTyped := reqVal.(type) {
+		case map[string]any:
+			curTyped, ok := curVal.(map[string]any)
+			if !ok {
+				continue
+			}
+			if mergeMissingKeys(curTyped, reqTyped) {
+				updated = true
+			}
+		case []any:
+			curTyped, ok := curVal.([]any)
+			if !ok {
+				continue
+			}
+			merged, changed := mergeMissingEntries(curTyped, reqTyped)
+			if changed {
+				existing[key] = merged
+				updated = true
+			}
+		default:
+			// Scalar the user already has a value for: leave it alone.
+		}
+	}
+	return updated
+}
+
+// mergeMissingEntries appends any entry in required that isn't already
+// present (by deep equality) in existing.
+func mergeMissingEntries(existing, required []any) ([]any, bool) {
+	updated := false
+	for _, reqEntry := range required {
+		if containsJSONEntry(existing, reqEntry) {
+			continue
+		}
+		existing = append(existing, reqEntry)
+		updated = true
+	}
+	return existing, updated
+}
+
+// mergeJSONArrayGenerate is mergeJSONGenerate's counterpart for config files
+// whose top level is a JSON array (Zed's tasks.json), appending any gastown
+// entry that isn't already present rather than merging object keys.
+func mergeJSONArrayGenerate(configPath, templateName string, cfg Config, opts SafeWriteOptions) error {
+	if cfg.MergePolicy == SkipIfExists {
+		if _, err := os.Stat(configPath); err == nil {
+			return nil
+		}
+	}
+
+	rendered, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return err
+	}
+
+	if cfg.MergePolicy != MergeMissing {
+		return SafeWrite(configPath, rendered, opts)
+	}
+
+	var required []any
+	if err := json.Unmarshal(rendered, &required); err != nil {
+		return fmt.Errorf("parsing rendered template %s: %w", templateName, err)
+	}
+
+	existing, err := readJSONArrayConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	merged, changed := mergeMissingEntries(existing, required)
+	if !changed {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if err := SafeWrite(configPath, data, opts); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readJSONArrayConfig reads and parses configPath as a top-level JSON array,
+// returning an empty slice if the file doesn't exist yet.
+func readJSONArrayConfig(path string) ([]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []any{}, nil
+		}
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return []any{}, nil
+	}
+	var existing []any
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	return existing, nil
+}
+
+// validateJSONArray is validateJSON's counterpart for config files whose top
+// level is a JSON array (Zed's tasks.json).
+func validateJSONArray(configPath, templateName string, cfg Config) error {
+	rendered, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return err
+	}
+	var required []any
+	if err := json.Unmarshal(rendered, &required); err != nil {
+		return fmt.Errorf("parsing rendered template %s: %w", templateName, err)
+	}
+
+	existing, err := readJSONArrayConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if _, changed := mergeMissingEntries(existing, required); changed {
+		return fmt.Errorf("%s is missing required gastown entries", configPath)
+	}
+	return nil
+}
+
+func containsJSONEntry(existing []any, entry any) bool {
+	target, err := json.Marshal(entry)
+	if err != nil {
+		return false
+	}
+	for _, candidate := range existing {
+		data, err := json.Marshal(candidate)
+		if err != nil {
+			continue
+		}
+		if string(data) == string(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// gastownBeginMarker and gastownEndMarker fence the region of a generated
+// markdown file (CopilotGenerator's copilot-instructions.md) that gastown
+// owns. mergeFencedTextBlock only ever reads or rewrites what's between a
+// begin/end marker pair, so anything a user wrote above or below the fence
+// survives a MergeMissing regeneration untouched. aiderBeginMarker/
+// aiderEndMarker are the same convention spelled as a YAML comment, for
+// AiderGenerator's .aider.conf.yml.
+const (
+	gastownBeginMarker = "<!-- gastown:begin -->"
+	gastownEndMarker   = "<!-- gastown:end -->"
+
+	aiderBeginMarker = "# gastown:begin"
+	aiderEndMarker   = "# gastown:end"
+)
+
+// mergeFencedTextBlock reconciles desired's gastown-owned fenced block into
+// existing, reporting whether anything changed. existing may be nil (no
+// file yet), may already carry a begin/end block from an earlier Generate,
+// or may be a file the user wrote by hand with no such block at all -- in
+// the last case the block is appended rather than merged in place, since
+// there's nothing to replace.
+func mergeFencedTextBlock(existing, desired []byte, beginMarker, endMarker string) ([]byte, bool) {
+	desiredBlock, ok := extractFencedBlock(desired, beginMarker, endMarker)
+	if !ok {
+		// The template itself has no fenced block; nothing to merge, so
+		// just treat desired as the whole file.
+		desiredBlock = desired
+	}
+
+	if len(existing) == 0 {
+		return desired, true
+	}
+
+	begin := bytes.Index(existing, []byte(beginMarker))
+	end := bytes.Index(existing, []byte(endMarker))
+	if begin < 0 || end < 0 || end < begin {
+		appended := append(append(append([]byte{}, existing...), []byte("\n\n")...), desiredBlock...)
+		return appended, true
+	}
+	end += len(endMarker)
+
+	if bytes.Equal(existing[begin:end], desiredBlock) {
+		return existing, false
+	}
+
+	merged := append([]byte{}, existing[:begin]...)
+	merged = append(merged, desiredBlock...)
+	merged = append(merged, existing[end:]...)
+	return merged, true
+}
+
+// extractFencedBlock returns the beginMarker...endMarker region of content,
+// including the markers themselves.
+func extractFencedBlock(content []byte, beginMarker, endMarker string) ([]byte, bool) {
+	begin := bytes.Index(content, []byte(beginMarker))
+	end := bytes.Index(content, []byte(endMarker))
+	if begin < 0 || end < 0 || end < begin {
+		return nil, false
+	}
+	end += len(endMarker)
+	return content[begin:end], true
+}
+
+// mergeFencedGenerate is the shared Generate() body for fenced-block text
+// generators (Copilot's markdown, Aider's YAML): it renders templateName,
+// then reconciles cfg's MergePolicy against whatever (if anything) already
+// exists at configPath, replacing only the region between beginMarker and
+// endMarker.
+func mergeFencedGenerate(configPath, templateName string, cfg Config, beginMarker, endMarker string, opts SafeWriteOptions) error {
+	plan, err := planFencedGenerate(configPath, templateName, cfg, beginMarker, endMarker)
+	if err != nil {
+		return err
+	}
+	return writePlan(plan, opts)
+}
+
+// planFencedGenerate mirrors mergeFencedGenerate's decision logic without
+// writing anything, so both Generate and Plan can share it.
+func planFencedGenerate(configPath, templateName string, cfg Config, beginMarker, endMarker string) (PlanResult, error) {
+	desired, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return PlanResult{}, err
+	}
+
+	if cfg.MergePolicy != MergeMissing {
+		return planWholeFile(configPath, desired, cfg)
+	}
+
+	existing, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return PlanResult{}, fmt.Errorf("reading config: %w", err)
+	}
+	merged, changed := mergeFencedTextBlock(existing, desired, beginMarker, endMarker)
+	if !changed {
+		return PlanResult{Path: configPath, Action: PlanSkip, Before: existing, After: existing}, nil
+	}
+	action := PlanCreate
+	if len(existing) > 0 {
+		action = PlanMerge
+	}
+	return PlanResult{Path: configPath, Action: action, Before: existing, After: merged}, nil
+}
+
+// validateFencedBlock reports, without writing anything, whether configPath
+// is missing (or has gone stale on) the gastown-owned fenced block embedded
+// in templateName.
+func validateFencedBlock(configPath, templateName string, cfg Config, beginMarker, endMarker string) error {
+	desired, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return err
+	}
+	desiredBlock, ok := extractFencedBlock(desired, beginMarker, endMarker)
+	if !ok {
+		return nil
+	}
+
+	existing, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	if !bytes.Contains(existing, desiredBlock) {
+		return fmt.Errorf("%s is missing required gastown entries", configPath)
+	}
+	return nil
+}
+
+// TemplateData is the variable set available inside embedded config
+// templates rendered by RenderTemplate.
+type TemplateData struct {
+	Role          string
+	RoleType      string
+	Autonomous    bool
+	WorkDir       string
+	GtPath        string
+	TownRoot      string
+	RigPath       string
+	SessionEnvVar string
+	Vars          map[string]string
+}
+
+// templateData builds the TemplateData for cfg, filling in the GtPath
+// default ("gt") the same way the CLI fallback commands do.
+func templateData(cfg Config) TemplateData {
+	gtPath := cfg.GtPath
+	if gtPath == "" {
+		gtPath = "gt"
+	}
+	return TemplateData{
+		Role:          cfg.Role,
+		RoleType:      string(cfg.RoleType),
+		Autonomous:    cfg.RoleType == Autonomous,
+		WorkDir:       cfg.WorkDir,
+		GtPath:        gtPath,
+		TownRoot:      cfg.TownRoot,
+		RigPath:       cfg.RigPath,
+		SessionEnvVar: cfg.SessionEnvVar,
+		Vars:          cfg.Vars,
+	}
+}
+
+// RenderTemplate reads the named embedded file and executes it as a
+// text/template against cfg's built-in variables (Role, RoleType,
+// Autonomous, WorkDir, GtPath, TownRoot, RigPath, SessionEnvVar) plus any
+// caller-supplied cfg.Vars. A template with no actions renders unchanged,
+// so existing static templates keep working as-is. Exported so other
+// packages (e.g. runtime.StartupFallbackCommands) can reuse the same
+// substitution rules instead of reimplementing them.
+func RenderTemplate(name string, cfg Config) ([]byte, error) {
+	raw, err := configFS.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading template %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(name)).Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData(cfg)); err != nil {
+		return nil, fmt.Errorf("executing template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
 }
 
 // Generator is the interface for agent config generators.
@@ -66,29 +881,105 @@ type Generator interface {
 	Generate(cfg Config) error
 	// Path returns the relative path where config will be written.
 	Path() string
+	// Detect reports whether this agent is already in play in workDir
+	// (its config directory exists, or its binary is on PATH), so the
+	// wizard can auto-select it.
+	Detect(workDir string) bool
+	// Validate reports whether this agent's existing config (if any) still
+	// has everything gastown requires, without writing anything. It
+	// returns nil if there's nothing to fix.
+	Validate(cfg Config) error
+	// Plan reports what Generate would do to this agent's config file for
+	// cfg, without writing anything. Generators that write more than one
+	// file (Zed's settings.json and tasks.json) report only the primary
+	// one returned by Path(), matching ResolvedPaths/RollbackAgent.
+	Plan(cfg Config) (PlanResult, error)
+}
+
+var (
+	generatorRegistry map[AgentType]func() Generator
+	generatorOrder    []AgentType
+)
+
+// Register adds (or replaces) the factory for agentType. Built-in
+// generators register themselves via init(); downstream packages can
+// Register generators for agent types gastown doesn't ship (Windsurf,
+// ...) without forking this package.
+func Register(agentType AgentType, factory func() Generator) {
+	if generatorRegistry == nil {
+		generatorRegistry = make(map[AgentType]func() Generator)
+	}
+	if _, exists := generatorRegistry[agentType]; !exists {
+		generatorOrder = append(generatorOrder, agentType)
+	}
+	generatorRegistry[agentType] = factory
+}
+
+func init() {
+	Register(AgentClaude, func() Generator { return &ClaudeGenerator{} })
+	Register(AgentCopilot, func() Generator { return &CopilotGenerator{} })
+	Register(AgentCursor, func() Generator { return &CursorGenerator{} })
+	Register(AgentMCP, func() Generator { return &MCPGenerator{} })
+	Register(AgentCLI, func() Generator { return &CLIGenerator{} })
+	Register(AgentAider, func() Generator { return &AiderGenerator{} })
+	Register(AgentZed, func() Generator { return &ZedGenerator{} })
+	Register(AgentContinue, func() Generator { return &ContinueGenerator{} })
 }
 
 // GetGenerator returns a generator for the specified agent type.
 func GetGenerator(agentType AgentType) (Generator, error) {
-	switch agentType {
-	case AgentClaude:
-		return &ClaudeGenerator{}, nil
-	case AgentCopilot:
-		return &CopilotGenerator{}, nil
-	case AgentCursor:
-		return &CursorGenerator{}, nil
-	case AgentMCP:
-		return &MCPGenerator{}, nil
-	case AgentCLI:
-		return &CLIGenerator{}, nil
-	default:
+	factory, ok := generatorRegistry[agentType]
+	if !ok {
 		return nil, fmt.Errorf("unknown agent type: %s", agentType)
 	}
+	return factory(), nil
 }
 
-// AllAgentTypes returns all supported agent types.
+// AllAgentTypes returns all registered agent types, in registration order.
 func AllAgentTypes() []AgentType {
-	return []AgentType{AgentClaude, AgentCopilot, AgentCursor, AgentMCP, AgentCLI}
+	out := make([]AgentType, len(generatorOrder))
+	copy(out, generatorOrder)
+	return out
+}
+
+// detectDir reports whether path exists (a directory for most agents, or
+// the config file itself for an agent with no subdirectory), or (if binary
+// is non-empty) binary is on PATH. Shared by the built-in generators'
+// Detect methods.
+func detectDir(path, binary string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	if binary != "" {
+		if _, err := exec.LookPath(binary); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// validateJSON reports, without writing anything, whether the existing
+// file at configPath is missing anything RenderTemplate(templateName, cfg)
+// would otherwise inject under MergeMissing.
+func validateJSON(configPath, templateName string, cfg Config) error {
+	rendered, err := RenderTemplate(templateName, cfg)
+	if err != nil {
+		return err
+	}
+	var required map[string]any
+	if err := json.Unmarshal(rendered, &required); err != nil {
+		return fmt.Errorf("parsing rendered template %s: %w", templateName, err)
+	}
+
+	existing, err := readJSONConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if mergeMissingKeys(existing, required) {
+		return fmt.Errorf("%s is missing required gastown entries", configPath)
+	}
+	return nil
 }
 
 // EnsureConfig ensures the configuration exists for the given agent type.
@@ -101,14 +992,34 @@ func EnsureConfig(agentType AgentType, cfg Config) error {
 	return gen.Generate(cfg)
 }
 
-// EnsureAllConfigs ensures configurations exist for all agent types.
-func EnsureAllConfigs(cfg Config) error {
+// EnsureAllConfigs ensures configurations exist for all agent types. In
+// dry-run mode (cfg.DryRun) nothing is written; instead it returns what
+// each agent's Generate would have done, via Generator.Plan. Outside
+// dry-run mode it always returns a nil []PlanResult.
+func EnsureAllConfigs(cfg Config) ([]PlanResult, error) {
+	if cfg.DryRun {
+		plans := make([]PlanResult, 0, len(AllAgentTypes()))
+		for _, agentType := range AllAgentTypes() {
+			gen, err := GetGenerator(agentType)
+			if err != nil {
+				return nil, fmt.Errorf("planning %s config: %w", agentType, err)
+			}
+			plan, err := gen.Plan(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("planning %s config: %w", agentType, err)
+			}
+			plan.AgentType = agentType
+			plans = append(plans, plan)
+		}
+		return plans, nil
+	}
+
 	for _, agentType := range AllAgentTypes() {
 		if err := EnsureConfig(agentType, cfg); err != nil {
-			return fmt.Errorf("generating %s config: %w", agentType, err)
+			return nil, fmt.Errorf("generating %s config: %w", agentType, err)
 		}
 	}
-	return nil
+	return nil, nil
 }
 
 // ClaudeGenerator generates .claude/settings.json configuration.
@@ -119,17 +1030,7 @@ func (g *ClaudeGenerator) Path() string {
 }
 
 func (g *ClaudeGenerator) Generate(cfg Config) error {
-	settingsPath := filepath.Join(cfg.WorkDir, g.Path())
-
-	// If settings already exist, don't overwrite
-	if _, err := os.Stat(settingsPath); err == nil {
-		return nil
-	}
-
-	// Create directory if needed
-	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
-		return fmt.Errorf("creating settings directory: %w", err)
-	}
+	settingsPath := resolvedConfigPath(AgentClaude, g.Path(), cfg)
 
 	// Select template based on role type
 	var templateName string
@@ -140,18 +1041,36 @@ func (g *ClaudeGenerator) Generate(cfg Config) error {
 		templateName = "config/claude-settings-interactive.json"
 	}
 
-	// Read template
-	content, err := configFS.ReadFile(templateName)
-	if err != nil {
-		return fmt.Errorf("reading template %s: %w", templateName, err)
-	}
+	return mergeJSONGenerate(settingsPath, templateName, cfg, SafeWriteOptions{Perm: 0600, Sensitive: true})
+}
+
+func (g *ClaudeGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentClaude, Config{WorkDir: workDir})
+	return detectDir(dir, "claude")
+}
 
-	// Write settings file
-	if err := os.WriteFile(settingsPath, content, 0600); err != nil {
-		return fmt.Errorf("writing settings: %w", err)
+func (g *ClaudeGenerator) Validate(cfg Config) error {
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/claude-settings-autonomous.json"
+	default:
+		templateName = "config/claude-settings-interactive.json"
 	}
+	return validateJSON(resolvedConfigPath(AgentClaude, g.Path(), cfg), templateName, cfg)
+}
 
-	return nil
+func (g *ClaudeGenerator) Plan(cfg Config) (PlanResult, error) {
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/claude-settings-autonomous.json"
+	default:
+		templateName = "config/claude-settings-interactive.json"
+	}
+	plan, err := planJSONGenerate(resolvedConfigPath(AgentClaude, g.Path(), cfg), templateName, cfg)
+	plan.AgentType = AgentClaude
+	return plan, err
 }
 
 // CopilotGenerator generates .github/copilot-instructions.md configuration.
@@ -162,17 +1081,7 @@ func (g *CopilotGenerator) Path() string {
 }
 
 func (g *CopilotGenerator) Generate(cfg Config) error {
-	configPath := filepath.Join(cfg.WorkDir, g.Path())
-
-	// If config already exists, don't overwrite
-	if _, err := os.Stat(configPath); err == nil {
-		return nil
-	}
-
-	// Create directory if needed
-	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
+	configPath := resolvedConfigPath(AgentCopilot, g.Path(), cfg)
 
 	// Select template based on role type
 	var templateName string
@@ -183,20 +1092,48 @@ func (g *CopilotGenerator) Generate(cfg Config) error {
 		templateName = "config/copilot-instructions-interactive.md"
 	}
 
-	// Read template
-	content, err := configFS.ReadFile(templateName)
+	return mergeFencedGenerate(configPath, templateName, cfg, gastownBeginMarker, gastownEndMarker, SafeWriteOptions{Perm: 0644})
+}
+
+func (g *CopilotGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentCopilot, Config{WorkDir: workDir})
+	return detectDir(dir, "copilot")
+}
+
+// Validate checks that an existing copilot-instructions.md still mentions
+// Gas Town. Markdown instructions aren't structured data, so unlike the
+// JSON generators this can't verify individual entries, only that the
+// gastown block hasn't been deleted wholesale. A file that doesn't exist
+// yet isn't an error here; SkipIfExists/MergeMissing policy decides whether
+// Generate should create one.
+func (g *CopilotGenerator) Validate(cfg Config) error {
+	configPath := resolvedConfigPath(AgentCopilot, g.Path(), cfg)
+	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return fmt.Errorf("reading template %s: %w", templateName, err)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading config: %w", err)
 	}
-
-	// Write config file
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
-		return fmt.Errorf("writing config: %w", err)
+	if !strings.Contains(string(data), "Gas Town") {
+		return fmt.Errorf("%s is missing the Gas Town instructions block", configPath)
 	}
-
 	return nil
 }
 
+func (g *CopilotGenerator) Plan(cfg Config) (PlanResult, error) {
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/copilot-instructions-autonomous.md"
+	default:
+		templateName = "config/copilot-instructions-interactive.md"
+	}
+	plan, err := planFencedGenerate(resolvedConfigPath(AgentCopilot, g.Path(), cfg), templateName, cfg, gastownBeginMarker, gastownEndMarker)
+	plan.AgentType = AgentCopilot
+	return plan, err
+}
+
 // CursorGenerator generates .cursor/mcp.json configuration.
 type CursorGenerator struct{}
 
@@ -205,30 +1142,23 @@ func (g *CursorGenerator) Path() string {
 }
 
 func (g *CursorGenerator) Generate(cfg Config) error {
-	configPath := filepath.Join(cfg.WorkDir, g.Path())
-
-	// If config already exists, don't overwrite
-	if _, err := os.Stat(configPath); err == nil {
-		return nil
-	}
-
-	// Create directory if needed
-	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
-	}
+	configPath := resolvedConfigPath(AgentCursor, g.Path(), cfg)
+	return mergeJSONGenerate(configPath, "config/cursor-mcp.json", cfg, SafeWriteOptions{Perm: 0644, Sensitive: true})
+}
 
-	// Read template
-	content, err := configFS.ReadFile("config/cursor-mcp.json")
-	if err != nil {
-		return fmt.Errorf("reading template: %w", err)
-	}
+func (g *CursorGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentCursor, Config{WorkDir: workDir})
+	return detectDir(dir, "cursor")
+}
 
-	// Write config file
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
-		return fmt.Errorf("writing config: %w", err)
-	}
+func (g *CursorGenerator) Validate(cfg Config) error {
+	return validateJSON(resolvedConfigPath(AgentCursor, g.Path(), cfg), "config/cursor-mcp.json", cfg)
+}
 
-	return nil
+func (g *CursorGenerator) Plan(cfg Config) (PlanResult, error) {
+	plan, err := planJSONGenerate(resolvedConfigPath(AgentCursor, g.Path(), cfg), "config/cursor-mcp.json", cfg)
+	plan.AgentType = AgentCursor
+	return plan, err
 }
 
 // MCPGenerator generates .mcp/servers.json configuration.
@@ -239,32 +1169,220 @@ func (g *MCPGenerator) Path() string {
 }
 
 func (g *MCPGenerator) Generate(cfg Config) error {
-	configPath := filepath.Join(cfg.WorkDir, g.Path())
+	configPath := resolvedConfigPath(AgentMCP, g.Path(), cfg)
+	return mergeJSONGenerate(configPath, "config/mcp-servers.json", cfg, SafeWriteOptions{Perm: 0644, Sensitive: true})
+}
 
-	// If config already exists, don't overwrite
-	if _, err := os.Stat(configPath); err == nil {
+func (g *MCPGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentMCP, Config{WorkDir: workDir})
+	return detectDir(dir, "")
+}
+
+func (g *MCPGenerator) Validate(cfg Config) error {
+	return validateJSON(resolvedConfigPath(AgentMCP, g.Path(), cfg), "config/mcp-servers.json", cfg)
+}
+
+func (g *MCPGenerator) Plan(cfg Config) (PlanResult, error) {
+	plan, err := planJSONGenerate(resolvedConfigPath(AgentMCP, g.Path(), cfg), "config/mcp-servers.json", cfg)
+	plan.AgentType = AgentMCP
+	return plan, err
+}
+
+// AiderGenerator generates .aider.conf.yml configuration.
+type AiderGenerator struct{}
+
+func (g *AiderGenerator) Path() string {
+	return ".aider.conf.yml"
+}
+
+func (g *AiderGenerator) Generate(cfg Config) error {
+	configPath := resolvedConfigPath(AgentAider, g.Path(), cfg)
+
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/aider-conf-autonomous.yml"
+	default:
+		templateName = "config/aider-conf-interactive.yml"
+	}
+
+	return mergeFencedGenerate(configPath, templateName, cfg, aiderBeginMarker, aiderEndMarker, SafeWriteOptions{Perm: 0644})
+}
+
+func (g *AiderGenerator) Detect(workDir string) bool {
+	path := resolvedConfigPath(AgentAider, g.Path(), Config{WorkDir: workDir})
+	return detectDir(path, "aider")
+}
+
+// Validate checks that an existing .aider.conf.yml still has the gastown
+// fenced block required for the current role. Like CopilotGenerator, this
+// can't verify individual YAML entries, only that the fenced block hasn't
+// been deleted or gone stale.
+func (g *AiderGenerator) Validate(cfg Config) error {
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/aider-conf-autonomous.yml"
+	default:
+		templateName = "config/aider-conf-interactive.yml"
+	}
+	return validateFencedBlock(resolvedConfigPath(AgentAider, g.Path(), cfg), templateName, cfg, aiderBeginMarker, aiderEndMarker)
+}
+
+func (g *AiderGenerator) Plan(cfg Config) (PlanResult, error) {
+	var templateName string
+	switch cfg.RoleType {
+	case Autonomous:
+		templateName = "config/aider-conf-autonomous.yml"
+	default:
+		templateName = "config/aider-conf-interactive.yml"
+	}
+	plan, err := planFencedGenerate(resolvedConfigPath(AgentAider, g.Path(), cfg), templateName, cfg, aiderBeginMarker, aiderEndMarker)
+	plan.AgentType = AgentAider
+	return plan, err
+}
+
+// ZedGenerator generates .zed/settings.json configuration, plus a
+// .zed/tasks.json entry for autonomous roles that runs the same session
+// bootstrap commands as the CLI fallback.
+type ZedGenerator struct{}
+
+func (g *ZedGenerator) Path() string {
+	return ".zed/settings.json"
+}
+
+func (g *ZedGenerator) Generate(cfg Config) error {
+	settingsPath := resolvedConfigPath(AgentZed, g.Path(), cfg)
+	if err := mergeJSONGenerate(settingsPath, "config/zed-settings.json", cfg, SafeWriteOptions{Perm: 0644, Sensitive: true}); err != nil {
+		return err
+	}
+
+	if cfg.RoleType != Autonomous {
 		return nil
 	}
 
-	// Create directory if needed
-	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+	tasksPath := resolvedConfigPath(AgentZed, ".zed/tasks.json", cfg)
+	return mergeJSONArrayGenerate(tasksPath, "config/zed-tasks.json", zedTasksConfig(cfg), SafeWriteOptions{Perm: 0644})
+}
+
+func (g *ZedGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentZed, Config{WorkDir: workDir})
+	return detectDir(dir, "zed")
+}
+
+func (g *ZedGenerator) Validate(cfg Config) error {
+	if err := validateJSON(resolvedConfigPath(AgentZed, g.Path(), cfg), "config/zed-settings.json", cfg); err != nil {
+		return err
+	}
+	if cfg.RoleType != Autonomous {
+		return nil
 	}
+	return validateJSONArray(resolvedConfigPath(AgentZed, ".zed/tasks.json", cfg), "config/zed-tasks.json", zedTasksConfig(cfg))
+}
 
-	// Read template
-	content, err := configFS.ReadFile("config/mcp-servers.json")
-	if err != nil {
-		return fmt.Errorf("reading template: %w", err)
+// Plan reports only on .zed/settings.json, matching Path(); tasks.json
+// (written only for autonomous roles) isn't covered, the same limitation
+// ResolvedPaths and RollbackAgent already have for Zed's second file.
+func (g *ZedGenerator) Plan(cfg Config) (PlanResult, error) {
+	plan, err := planJSONGenerate(resolvedConfigPath(AgentZed, g.Path(), cfg), "config/zed-settings.json", cfg)
+	plan.AgentType = AgentZed
+	return plan, err
+}
+
+// zedTasksConfig copies cfg with an extra FallbackCommand template variable
+// set to the same session bootstrap command CLIGenerator.GetCommandString
+// would give an agent without hook support, for use by zed-tasks.json.
+func zedTasksConfig(cfg Config) Config {
+	taskCfg := cfg
+	taskCfg.Vars = make(map[string]string, len(cfg.Vars)+1)
+	for k, v := range cfg.Vars {
+		taskCfg.Vars[k] = v
 	}
+	taskCfg.Vars["FallbackCommand"] = (&CLIGenerator{}).GetCommandString(cfg)
+	return taskCfg
+}
 
-	// Write config file
-	if err := os.WriteFile(configPath, content, 0644); err != nil {
-		return fmt.Errorf("writing config: %w", err)
+// ContinueGenerator generates .continue/config.json configuration.
+type ContinueGenerator struct{}
+
+func (g *ContinueGenerator) Path() string {
+	return ".continue/config.json"
+}
+
+func (g *ContinueGenerator) Generate(cfg Config) error {
+	required, err := continueRequiredConfig(cfg)
+	if err != nil {
+		return err
 	}
+	return mergeJSONGenerateFromMap(resolvedConfigPath(AgentContinue, g.Path(), cfg), required, cfg, SafeWriteOptions{Perm: 0644, Sensitive: true})
+}
 
+func (g *ContinueGenerator) Detect(workDir string) bool {
+	dir := PathResolver{}.Resolve(AgentContinue, Config{WorkDir: workDir})
+	return detectDir(dir, "continue")
+}
+
+func (g *ContinueGenerator) Validate(cfg Config) error {
+	required, err := continueRequiredConfig(cfg)
+	if err != nil {
+		return err
+	}
+	configPath := resolvedConfigPath(AgentContinue, g.Path(), cfg)
+	existing, err := readJSONConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if mergeMissingKeys(existing, required) {
+		return fmt.Errorf("%s is missing required gastown entries", configPath)
+	}
 	return nil
 }
 
+func (g *ContinueGenerator) Plan(cfg Config) (PlanResult, error) {
+	required, err := continueRequiredConfig(cfg)
+	if err != nil {
+		return PlanResult{}, err
+	}
+	plan, err := planJSONGenerateFromMap(resolvedConfigPath(AgentContinue, g.Path(), cfg), required, cfg)
+	plan.AgentType = AgentContinue
+	return plan, err
+}
+
+// continueRequiredConfig builds the gastown-owned portion of
+// .continue/config.json: an mcpServers entry identical to the other
+// generators', plus a systemMessage carrying the same instructions text
+// CopilotGenerator renders into copilot-instructions.md.
+func continueRequiredConfig(cfg Config) (map[string]any, error) {
+	var copilotTemplate string
+	switch cfg.RoleType {
+	case Autonomous:
+		copilotTemplate = "config/copilot-instructions-autonomous.md"
+	default:
+		copilotTemplate = "config/copilot-instructions-interactive.md"
+	}
+	rendered, err := RenderTemplate(copilotTemplate, cfg)
+	if err != nil {
+		return nil, err
+	}
+	block, ok := extractFencedBlock(rendered, gastownBeginMarker, gastownEndMarker)
+	if !ok {
+		block = rendered
+	}
+	systemMessage := strings.TrimSpace(
+		strings.NewReplacer(gastownBeginMarker, "", gastownEndMarker, "").Replace(string(block)),
+	)
+
+	return map[string]any{
+		"mcpServers": map[string]any{
+			"gastown": map[string]any{
+				"command": templateData(cfg).GtPath,
+				"args":    []any{"mcp", "serve"},
+			},
+		},
+		"systemMessage": systemMessage,
+	}, nil
+}
+
 // CLIGenerator generates CLI fallback commands (no file output).
 type CLIGenerator struct{}
 
@@ -272,11 +1390,27 @@ func (g *CLIGenerator) Path() string {
 	return "" // CLI generator doesn't write files
 }
 
+// Detect always reports true: the CLI fallback has no file footprint and
+// works regardless of what's installed.
+func (g *CLIGenerator) Detect(workDir string) bool {
+	return true
+}
+
+// Validate is a no-op: there's no file for the CLI fallback to drift.
+func (g *CLIGenerator) Validate(cfg Config) error {
+	return nil
+}
+
 func (g *CLIGenerator) Generate(cfg Config) error {
 	// CLI generator doesn't write files - it generates commands via GetCommands
 	return nil
 }
 
+func (g *CLIGenerator) Plan(cfg Config) (PlanResult, error) {
+	// CLI generator doesn't write files, so there's nothing to plan.
+	return PlanResult{AgentType: AgentCLI, Action: PlanSkip}, nil
+}
+
 // GetCommands returns CLI fallback commands for agents without hook support.
 func (g *CLIGenerator) GetCommands(cfg Config) []string {
 	gtPath := cfg.GtPath
@@ -354,18 +1488,125 @@ func BuildMCPServersConfig(gtPath string) *MCPServersConfig {
 
 // WriteMCPServersConfig writes the MCP servers configuration to the given path.
 func WriteMCPServersConfig(path string, config *MCPServersConfig) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("creating directory: %w", err)
-	}
-
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("writing config: %w", err)
+	return SafeWrite(path, data, SafeWriteOptions{Perm: 0644, Sensitive: true})
+}
+
+// RenderUnifiedDiff renders a `diff -u`-style unified diff for every plan
+// whose Before and After differ, in the order given, skipping anything
+// PlanSkip (or where the two are identical anyway). This is the library
+// call a `gt speckit ensure --dry-run` invocation would feed to the
+// terminal to show a user how their configs would change; gastown's CLI
+// entrypoint lives outside this package, so no flag wiring is added here.
+func RenderUnifiedDiff(plans []PlanResult) string {
+	var buf strings.Builder
+	for _, plan := range plans {
+		if plan.Action == PlanSkip || bytes.Equal(plan.Before, plan.After) {
+			continue
+		}
+		buf.WriteString(unifiedDiff(plan.Path, plan.Before, plan.After))
 	}
+	return buf.String()
+}
 
-	return nil
+// unifiedDiff renders a single diff -u-style patch of before against after,
+// as one hunk covering the whole file. Config files are small, so there's
+// no need for the context-trimming real diff tools do around large
+// unchanged regions; diffLines below is a small in-repo line differ sized
+// for that, not for arbitrary source trees.
+func unifiedDiff(path string, before, after []byte) string {
+	beforeLines := splitLines(string(before))
+	afterLines := splitLines(string(after))
+	ops := diffLines(beforeLines, afterLines)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- a/%s\n", path)
+	fmt.Fprintf(&buf, "+++ b/%s\n", path)
+	fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(len(beforeLines)), hunkRange(len(afterLines)))
+	for _, op := range ops {
+		buf.WriteByte(op.kind)
+		buf.WriteString(op.line)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// hunkRange renders a unified-diff hunk header's line count for a side with
+// count lines, starting at line 1 (or "0,0" for an empty side).
+func hunkRange(count int) string {
+	if count == 0 {
+		return "0,0"
+	}
+	return fmt.Sprintf("1,%d", count)
+}
+
+// splitLines splits s on "\n" into lines with the trailing newline's empty
+// final element dropped, and nil for an empty string.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is one line of a unified diff body: unchanged (' '), removed
+// ('-'), or added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes the minimal line-level edit script turning before
+// into after, via the standard LCS dynamic-programming table. It's O(n*m)
+// in line count, which is fine for the short generated config files this
+// package deals in.
+func diffLines(before, after []string) []diffOp {
+	n, m := len(before), len(after)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case before[i] == after[j]:
+			ops = append(ops, diffOp{' ', before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', before[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', after[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', before[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', after[j]})
+	}
+	return ops
 }
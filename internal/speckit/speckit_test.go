@@ -1,8 +1,11 @@
 package speckit
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -61,16 +64,19 @@ func TestGetGenerator(t *testing.T) {
 
 func TestAllAgentTypes(t *testing.T) {
 	types := AllAgentTypes()
-	if len(types) != 5 {
-		t.Errorf("AllAgentTypes() returned %d types, want 5", len(types))
+	if len(types) != 8 {
+		t.Errorf("AllAgentTypes() returned %d types, want 8", len(types))
 	}
 
 	expected := map[AgentType]bool{
-		AgentClaude:  false,
-		AgentCopilot: false,
-		AgentCursor:  false,
-		AgentMCP:     false,
-		AgentCLI:     false,
+		AgentClaude:   false,
+		AgentCopilot:  false,
+		AgentCursor:   false,
+		AgentMCP:      false,
+		AgentCLI:      false,
+		AgentAider:    false,
+		AgentZed:      false,
+		AgentContinue: false,
 	}
 
 	for _, at := range types {
@@ -87,6 +93,103 @@ func TestAllAgentTypes(t *testing.T) {
 	}
 }
 
+type fakeGenerator struct{}
+
+func (fakeGenerator) Generate(cfg Config) error  { return nil }
+func (fakeGenerator) Path() string               { return ".fake/config.json" }
+func (fakeGenerator) Detect(workDir string) bool { return false }
+func (fakeGenerator) Validate(cfg Config) error  { return nil }
+func (fakeGenerator) Plan(cfg Config) (PlanResult, error) {
+	return PlanResult{Action: PlanSkip}, nil
+}
+
+func TestRegister_AddsDownstreamAgentType(t *testing.T) {
+	const fakeType AgentType = "fake-agent"
+	Register(fakeType, func() Generator { return fakeGenerator{} })
+
+	gen, err := GetGenerator(fakeType)
+	if err != nil {
+		t.Fatalf("GetGenerator(%q) error = %v", fakeType, err)
+	}
+	if gen.Path() != ".fake/config.json" {
+		t.Errorf("GetGenerator(%q).Path() = %q, want %q", fakeType, gen.Path(), ".fake/config.json")
+	}
+
+	found := false
+	for _, at := range AllAgentTypes() {
+		if at == fakeType {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("AllAgentTypes() = %v, want to include downstream-registered %q", AllAgentTypes(), fakeType)
+	}
+}
+
+func TestClaudeGenerator_DetectAndValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	gen := &ClaudeGenerator{}
+
+	if gen.Detect(tmpDir) {
+		t.Error("Detect() = true before .claude/ exists, want false")
+	}
+
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous}
+	if err := gen.Validate(cfg); err == nil {
+		t.Error("Validate() = nil before settings.json exists, want an error")
+	}
+
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if !gen.Detect(tmpDir) {
+		t.Error("Detect() = false after .claude/settings.json was written, want true")
+	}
+	if err := gen.Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v after Generate(), want nil", err)
+	}
+}
+
+func TestClaudeGenerator_Plan(t *testing.T) {
+	tmpDir := t.TempDir()
+	gen := &ClaudeGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous}
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+
+	plan, err := gen.Plan(cfg)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.AgentType != AgentClaude {
+		t.Errorf("Plan().AgentType = %q, want %q", plan.AgentType, AgentClaude)
+	}
+	if plan.Path != settingsPath {
+		t.Errorf("Plan().Path = %q, want %q", plan.Path, settingsPath)
+	}
+	if plan.Action != PlanCreate {
+		t.Errorf("Plan().Action = %v, want PlanCreate before settings.json exists", plan.Action)
+	}
+	if len(plan.Before) != 0 {
+		t.Errorf("Plan().Before = %q, want empty before settings.json exists", plan.Before)
+	}
+	if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+		t.Error("Plan() should not have written settings.json")
+	}
+
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	plan, err = gen.Plan(cfg)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.Action != PlanSkip {
+		t.Errorf("Plan().Action = %v, want PlanSkip once settings.json exists under SkipIfExists", plan.Action)
+	}
+}
+
 func TestClaudeGenerator(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -126,6 +229,60 @@ func TestClaudeGenerator(t *testing.T) {
 	}
 }
 
+func TestClaudeGenerator_MergeMissingPreservesUserHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsPath := filepath.Join(tmpDir, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	existing := map[string]any{
+		"permissions": map[string]any{
+			"additionalDirectories": []any{"/some/other/dir"},
+		},
+		"hooks": map[string]any{
+			"PreToolUse": []any{
+				map[string]any{
+					"matcher": "",
+					"hooks":   []any{map[string]any{"type": "command", "command": "echo user-hook"}},
+				},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal existing config: %v", err)
+	}
+	if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+		t.Fatalf("write existing config: %v", err)
+	}
+
+	gen := &ClaudeGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous, MergePolicy: MergeMissing}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	merged, err := readJSONConfig(settingsPath)
+	if err != nil {
+		t.Fatalf("read merged config: %v", err)
+	}
+
+	permissions, _ := merged["permissions"].(map[string]any)
+	dirs, _ := permissions["additionalDirectories"].([]any)
+	if len(dirs) != 1 || dirs[0] != "/some/other/dir" {
+		t.Errorf("MergeMissing should preserve the user's own additionalDirectories entry, got %v", dirs)
+	}
+
+	hooks, _ := merged["hooks"].(map[string]any)
+	if _, ok := hooks["PreToolUse"]; !ok {
+		t.Error("MergeMissing should preserve the user's own PreToolUse hook")
+	}
+	if _, ok := hooks["SessionStart"]; !ok {
+		t.Error("MergeMissing should inject the gastown SessionStart hook")
+	}
+}
+
 func TestClaudeGeneratorInteractive(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -191,6 +348,46 @@ func TestCopilotGenerator(t *testing.T) {
 	}
 }
 
+func TestCopilotGenerator_MergeMissingReplacesOnlyGastownBlock(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".github", "copilot-instructions.md")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	existing := "# My Project Instructions\n\n" +
+		"Some hand-written guidance that must survive.\n\n" +
+		gastownBeginMarker + "\nstale gastown content\n" + gastownEndMarker + "\n\n" +
+		"More hand-written guidance below the block.\n"
+	if err := os.WriteFile(configPath, []byte(existing), 0644); err != nil {
+		t.Fatalf("write existing config: %v", err)
+	}
+
+	gen := &CopilotGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous, MergePolicy: MergeMissing}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if !strings.Contains(string(content), "Some hand-written guidance that must survive.") {
+		t.Error("MergeMissing should preserve content above the gastown block")
+	}
+	if !strings.Contains(string(content), "More hand-written guidance below the block.") {
+		t.Error("MergeMissing should preserve content below the gastown block")
+	}
+	if strings.Contains(string(content), "stale gastown content") {
+		t.Error("MergeMissing should replace the stale gastown block, not leave it in place")
+	}
+	if !strings.Contains(string(content), "mail check --inject") {
+		t.Error("MergeMissing should inject the current gastown block")
+	}
+}
+
 func TestCursorGenerator(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -248,6 +445,365 @@ func TestMCPGenerator(t *testing.T) {
 	}
 }
 
+func TestMCPGenerator_MergeMissingPreservesUserServers(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".mcp", "servers.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	existing := map[string]any{
+		"mcpServers": map[string]any{
+			"my-own-server": map[string]any{"command": "my-tool"},
+		},
+	}
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal existing config: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("write existing config: %v", err)
+	}
+
+	gen := &MCPGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous, MergePolicy: MergeMissing}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("MCPGenerator.Generate() error = %v", err)
+	}
+
+	merged, err := readJSONConfig(configPath)
+	if err != nil {
+		t.Fatalf("read merged config: %v", err)
+	}
+	servers, _ := merged["mcpServers"].(map[string]any)
+	if _, ok := servers["my-own-server"]; !ok {
+		t.Error("MergeMissing should preserve the user's own MCP server entry")
+	}
+	if _, ok := servers["gastown"]; !ok {
+		t.Error("MergeMissing should inject the gastown MCP server entry")
+	}
+}
+
+func TestMCPGenerator_MergeMissingNoopsWhenAlreadyComplete(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".mcp", "servers.json")
+
+	gen := &MCPGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous, MergePolicy: MergeMissing}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("first Generate() error = %v", err)
+	}
+
+	infoBefore, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("stat before: %v", err)
+	}
+
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("second Generate() error = %v", err)
+	}
+
+	infoAfter, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("stat after: %v", err)
+	}
+	if !infoAfter.ModTime().Equal(infoBefore.ModTime()) {
+		t.Error("MergeMissing should not rewrite a file that's already complete")
+	}
+}
+
+func TestAiderGenerator(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gen := &AiderGenerator{}
+	if gen.Path() != ".aider.conf.yml" {
+		t.Errorf("AiderGenerator.Path() = %q, want %q", gen.Path(), ".aider.conf.yml")
+	}
+
+	cfg := Config{
+		WorkDir:  tmpDir,
+		RoleType: Autonomous,
+	}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("AiderGenerator.Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, ".aider.conf.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+
+	if !strings.Contains(string(content), "mcp-servers") {
+		t.Error("Aider config should contain mcp-servers")
+	}
+	if !strings.Contains(string(content), "MAIL.md") {
+		t.Error("Autonomous Aider config should read MAIL.md")
+	}
+
+	if err := gen.Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v after a fresh Generate()", err)
+	}
+}
+
+func TestAiderGenerator_MergeMissingReplacesOnlyGastownBlock(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".aider.conf.yml")
+
+	existing := "# hand-edited aider config\n" +
+		"auto-commits: false\n\n" +
+		aiderBeginMarker + "\nstale gastown content\n" + aiderEndMarker + "\n"
+	if err := os.WriteFile(configPath, []byte(existing), 0644); err != nil {
+		t.Fatalf("write existing config: %v", err)
+	}
+
+	gen := &AiderGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Interactive, MergePolicy: MergeMissing}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(content), "auto-commits: false") {
+		t.Error("MergeMissing should preserve the user's hand-written settings")
+	}
+	if strings.Contains(string(content), "stale gastown content") {
+		t.Error("MergeMissing should replace the stale gastown block")
+	}
+	if !strings.Contains(string(content), "AGENTS.md") {
+		t.Error("MergeMissing should inject the current gastown block")
+	}
+}
+
+func TestZedGenerator(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gen := &ZedGenerator{}
+	if gen.Path() != ".zed/settings.json" {
+		t.Errorf("ZedGenerator.Path() = %q, want %q", gen.Path(), ".zed/settings.json")
+	}
+
+	cfg := Config{
+		WorkDir:  tmpDir,
+		RoleType: Autonomous,
+	}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("ZedGenerator.Generate() error = %v", err)
+	}
+
+	settings, err := os.ReadFile(filepath.Join(tmpDir, ".zed", "settings.json"))
+	if err != nil {
+		t.Fatalf("Failed to read settings: %v", err)
+	}
+	if !strings.Contains(string(settings), "context_servers") {
+		t.Error("Zed settings should contain context_servers")
+	}
+
+	tasks, err := os.ReadFile(filepath.Join(tmpDir, ".zed", "tasks.json"))
+	if err != nil {
+		t.Fatalf("Autonomous role should get a tasks.json: %v", err)
+	}
+	if !strings.Contains(string(tasks), "mail check --inject") {
+		t.Error("Autonomous tasks.json should run the mail check --inject fallback command")
+	}
+
+	if err := gen.Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v after a fresh Generate()", err)
+	}
+}
+
+func TestZedGenerator_InteractiveSkipsTasksFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gen := &ZedGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Interactive}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".zed", "tasks.json")); !os.IsNotExist(err) {
+		t.Error("Interactive role should not get a tasks.json")
+	}
+}
+
+func TestContinueGenerator(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	gen := &ContinueGenerator{}
+	if gen.Path() != ".continue/config.json" {
+		t.Errorf("ContinueGenerator.Path() = %q, want %q", gen.Path(), ".continue/config.json")
+	}
+
+	cfg := Config{
+		WorkDir:  tmpDir,
+		RoleType: Autonomous,
+	}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("ContinueGenerator.Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, ".continue", "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(content), "mcpServers") {
+		t.Error("Continue config should contain mcpServers")
+	}
+	if !strings.Contains(string(content), "systemMessage") {
+		t.Error("Continue config should contain systemMessage")
+	}
+	if !strings.Contains(string(content), "mail check --inject") {
+		t.Error("Autonomous systemMessage should mention mail check --inject")
+	}
+
+	if err := gen.Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v after a fresh Generate()", err)
+	}
+}
+
+func TestPathResolver_DefaultsToWorkDirRelative(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir}
+
+	got := PathResolver{}.Resolve(AgentClaude, cfg)
+	want := filepath.Join(tmpDir, ".claude")
+	if got != want {
+		t.Errorf("Resolve(AgentClaude) = %q, want %q", got, want)
+	}
+}
+
+func TestPathResolver_AgentSpecificEnvVarWins(t *testing.T) {
+	tmpDir := t.TempDir()
+	override := filepath.Join(tmpDir, "override")
+	t.Setenv("GASTOWN_CLAUDE_DIR", override)
+	t.Setenv("CLAUDE_CONFIG_HOME", filepath.Join(tmpDir, "unused"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "also-unused"))
+
+	cfg := Config{WorkDir: tmpDir}
+	got := PathResolver{}.Resolve(AgentClaude, cfg)
+	if got != override {
+		t.Errorf("Resolve(AgentClaude) = %q, want %q", got, override)
+	}
+}
+
+func TestPathResolver_SecondEnvVarWinsOverXDG(t *testing.T) {
+	tmpDir := t.TempDir()
+	fallback := filepath.Join(tmpDir, "fallback")
+	t.Setenv("CLAUDE_CONFIG_HOME", fallback)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "xdg"))
+
+	cfg := Config{WorkDir: tmpDir}
+	got := PathResolver{}.Resolve(AgentClaude, cfg)
+	if got != fallback {
+		t.Errorf("Resolve(AgentClaude) = %q, want %q", got, fallback)
+	}
+}
+
+func TestPathResolver_XDGConfigHomeWinsOverDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	xdg := filepath.Join(tmpDir, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	cfg := Config{WorkDir: tmpDir}
+	got := PathResolver{}.Resolve(AgentClaude, cfg)
+	want := filepath.Join(xdg, "claude")
+	if got != want {
+		t.Errorf("Resolve(AgentClaude) = %q, want %q", got, want)
+	}
+}
+
+func TestPathResolver_AiderHasNoSubdirDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir}
+
+	got := PathResolver{}.Resolve(AgentAider, cfg)
+	if got != tmpDir {
+		t.Errorf("Resolve(AgentAider) = %q, want %q", got, tmpDir)
+	}
+}
+
+func TestPathResolver_UnknownAgentTypeFallsBackToWorkDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir}
+
+	got := PathResolver{}.Resolve(AgentCLI, cfg)
+	if got != tmpDir {
+		t.Errorf("Resolve(AgentCLI) = %q, want %q", got, tmpDir)
+	}
+}
+
+func TestResolvedPaths_DefaultsMatchHardcodedGeneratorPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir}
+
+	paths, err := ResolvedPaths(cfg)
+	if err != nil {
+		t.Fatalf("ResolvedPaths() error = %v", err)
+	}
+
+	want := map[AgentType]string{
+		AgentClaude:   filepath.Join(tmpDir, ".claude", "settings.json"),
+		AgentCopilot:  filepath.Join(tmpDir, ".github", "copilot-instructions.md"),
+		AgentCursor:   filepath.Join(tmpDir, ".cursor", "mcp.json"),
+		AgentMCP:      filepath.Join(tmpDir, ".mcp", "servers.json"),
+		AgentAider:    filepath.Join(tmpDir, ".aider.conf.yml"),
+		AgentZed:      filepath.Join(tmpDir, ".zed", "settings.json"),
+		AgentContinue: filepath.Join(tmpDir, ".continue", "config.json"),
+	}
+	for agentType, wantPath := range want {
+		if paths[agentType] != wantPath {
+			t.Errorf("ResolvedPaths()[%s] = %q, want %q", agentType, paths[agentType], wantPath)
+		}
+	}
+	if _, ok := paths[AgentCLI]; ok {
+		t.Error("ResolvedPaths() should omit AgentCLI, which has no config file")
+	}
+}
+
+func TestResolvedPaths_HonorsEnvOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	override := filepath.Join(tmpDir, "override")
+	t.Setenv("GASTOWN_MCP_DIR", override)
+
+	paths, err := ResolvedPaths(Config{WorkDir: tmpDir})
+	if err != nil {
+		t.Fatalf("ResolvedPaths() error = %v", err)
+	}
+	want := filepath.Join(override, "servers.json")
+	if paths[AgentMCP] != want {
+		t.Errorf("ResolvedPaths()[AgentMCP] = %q, want %q", paths[AgentMCP], want)
+	}
+}
+
+func TestClaudeGenerator_HonorsPathResolverOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	overrideDir := filepath.Join(tmpDir, "elsewhere")
+	t.Setenv("GASTOWN_CLAUDE_DIR", overrideDir)
+
+	gen := &ClaudeGenerator{}
+	cfg := Config{WorkDir: tmpDir, RoleType: Interactive}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".claude", "settings.json")); !os.IsNotExist(err) {
+		t.Error("Generate() should not have written under the WorkDir-relative default once GASTOWN_CLAUDE_DIR is set")
+	}
+	if _, err := os.Stat(filepath.Join(overrideDir, "settings.json")); err != nil {
+		t.Errorf("Generate() should have written settings.json under GASTOWN_CLAUDE_DIR: %v", err)
+	}
+
+	if err := gen.Validate(cfg); err != nil {
+		t.Errorf("Validate() error = %v after a fresh Generate() at the overridden path", err)
+	}
+
+	if !gen.Detect(tmpDir) {
+		t.Error("Detect() should find the overridden config dir even though it's outside WorkDir")
+	}
+}
+
 func TestCLIGenerator(t *testing.T) {
 	gen := &CLIGenerator{}
 	if gen.Path() != "" {
@@ -341,8 +897,10 @@ func TestEnsureAllConfigs(t *testing.T) {
 		RoleType: Autonomous,
 	}
 
-	if err := EnsureAllConfigs(cfg); err != nil {
+	if plans, err := EnsureAllConfigs(cfg); err != nil {
 		t.Errorf("EnsureAllConfigs() error = %v", err)
+	} else if plans != nil {
+		t.Errorf("EnsureAllConfigs() plans = %v, want nil outside dry-run mode", plans)
 	}
 
 	// Verify all config files were created
@@ -351,6 +909,10 @@ func TestEnsureAllConfigs(t *testing.T) {
 		".github/copilot-instructions.md",
 		".cursor/mcp.json",
 		".mcp/servers.json",
+		".aider.conf.yml",
+		".zed/settings.json",
+		".zed/tasks.json",
+		".continue/config.json",
 	}
 
 	for _, path := range expectedPaths {
@@ -361,6 +923,123 @@ func TestEnsureAllConfigs(t *testing.T) {
 	}
 }
 
+func TestEnsureAllConfigs_DryRunWritesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous, DryRun: true}
+
+	plans, err := EnsureAllConfigs(cfg)
+	if err != nil {
+		t.Fatalf("EnsureAllConfigs() error = %v", err)
+	}
+	if len(plans) != len(AllAgentTypes()) {
+		t.Errorf("EnsureAllConfigs() returned %d plans, want %d", len(plans), len(AllAgentTypes()))
+	}
+
+	fileWritingTypes := map[AgentType]bool{
+		AgentClaude: true, AgentCopilot: true, AgentCursor: true, AgentMCP: true,
+		AgentAider: true, AgentZed: true, AgentContinue: true,
+	}
+	for _, plan := range plans {
+		if !fileWritingTypes[plan.AgentType] {
+			continue
+		}
+		if plan.Action != PlanCreate {
+			t.Errorf("plan for %s: Action = %v, want PlanCreate (nothing on disk yet)", plan.AgentType, plan.Action)
+		}
+		if len(plan.After) == 0 {
+			t.Errorf("plan for %s: After is empty", plan.AgentType)
+		}
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadDir(%s) error = %v", tmpDir, err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("EnsureAllConfigs(DryRun) wrote to %s, want nothing written: %v", tmpDir, entries)
+	}
+}
+
+func TestRenderUnifiedDiff(t *testing.T) {
+	plans := []PlanResult{
+		{Path: "a.json", Action: PlanSkip, Before: []byte(`{"x":1}`), After: []byte(`{"x":1}`)},
+		{Path: "b.json", Action: PlanCreate, Before: nil, After: []byte("line1\nline2\n")},
+		{Path: "c.json", Action: PlanMerge, Before: []byte("kept\nold\n"), After: []byte("kept\nnew\n")},
+	}
+
+	diff := RenderUnifiedDiff(plans)
+
+	if strings.Contains(diff, "a.json") {
+		t.Error("RenderUnifiedDiff() should skip PlanSkip entries")
+	}
+	if !strings.Contains(diff, "--- a/b.json") || !strings.Contains(diff, "+++ b/b.json") {
+		t.Error("RenderUnifiedDiff() should header b.json's hunk")
+	}
+	if !strings.Contains(diff, "+line1") || !strings.Contains(diff, "+line2") {
+		t.Error("RenderUnifiedDiff() should show b.json's new lines as additions")
+	}
+	if !strings.Contains(diff, " kept") {
+		t.Error("RenderUnifiedDiff() should show c.json's unchanged line with a context prefix")
+	}
+	if !strings.Contains(diff, "-old") || !strings.Contains(diff, "+new") {
+		t.Error("RenderUnifiedDiff() should show c.json's changed line as a removal and an addition")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	cfg := Config{
+		Role:     "witness",
+		RoleType: Autonomous,
+		WorkDir:  "/tmp/rig",
+		GtPath:   "",
+		Vars:     map[string]string{"Extra": "custom-value"},
+	}
+
+	content, err := RenderTemplate("config/claude-settings-autonomous.json", cfg)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	// GtPath defaults to "gt" when unset, same as the CLI fallback commands.
+	if !strings.Contains(string(content), "mail check --inject") {
+		t.Error("Autonomous settings should still contain mail check after templating")
+	}
+
+	if _, err := RenderTemplate("config/does-not-exist.json", cfg); err == nil {
+		t.Error("RenderTemplate() with unknown template should return an error")
+	}
+}
+
+// TestConfigTemplatesEmbedded guards against the //go:embed directive and
+// the config/ directory drifting apart: every template name a generator
+// references must actually be present in configFS, or the package fails
+// to build (go:embed requires at least one match per pattern, and a
+// missing individual file surfaces only at RenderTemplate time). Listing
+// the names explicitly here, rather than just relying on generator tests,
+// keeps this failing loudly if a template is ever renamed or deleted
+// without updating its caller.
+func TestConfigTemplatesEmbedded(t *testing.T) {
+	names := []string{
+		"config/aider-conf-autonomous.yml",
+		"config/aider-conf-interactive.yml",
+		"config/claude-settings-autonomous.json",
+		"config/claude-settings-interactive.json",
+		"config/copilot-instructions-autonomous.md",
+		"config/copilot-instructions-interactive.md",
+		"config/cursor-mcp.json",
+		"config/mcp-servers.json",
+		"config/zed-settings.json",
+		"config/zed-tasks.json",
+	}
+
+	cfg := Config{Role: "witness", RoleType: Autonomous, WorkDir: "/tmp/rig"}
+	for _, name := range names {
+		if _, err := RenderTemplate(name, cfg); err != nil {
+			t.Errorf("RenderTemplate(%q) error = %v, want template embedded and renderable", name, err)
+		}
+	}
+}
+
 func TestBuildMCPServersConfig(t *testing.T) {
 	config := BuildMCPServersConfig("")
 	if config == nil {
@@ -409,3 +1088,204 @@ func TestWriteMCPServersConfig(t *testing.T) {
 		t.Error("Written config should contain gastown")
 	}
 }
+
+func TestSafeWrite_WritesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "subdir", "config.json")
+
+	if err := SafeWrite(path, []byte("hello"), SafeWriteOptions{}); err != nil {
+		t.Fatalf("SafeWrite() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("content = %q, want %q", content, "hello")
+	}
+
+	leftovers, err := filepath.Glob(path + ".tmp-*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("SafeWrite should not leave temp files behind, found %v", leftovers)
+	}
+}
+
+func TestSafeWrite_SensitiveForces0600(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permission bits don't apply on windows")
+	}
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	if err := SafeWrite(path, []byte("{}"), SafeWriteOptions{Perm: 0644, Sensitive: true}); err != nil {
+		t.Fatalf("SafeWrite() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %o, want 0600 (Sensitive should override Perm)", info.Mode().Perm())
+	}
+}
+
+func TestSafeWrite_RotatesBackups(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+	opts := SafeWriteOptions{KeepBackups: 2}
+
+	for _, gen := range []string{"v1", "v2", "v3"} {
+		if err := SafeWrite(path, []byte(gen), opts); err != nil {
+			t.Fatalf("SafeWrite(%s) error = %v", gen, err)
+		}
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile current: %v", err)
+	}
+	if string(current) != "v3" {
+		t.Errorf("current content = %q, want %q", current, "v3")
+	}
+
+	bak1, err := os.ReadFile(path + ".bak.1")
+	if err != nil {
+		t.Fatalf("ReadFile bak.1: %v", err)
+	}
+	if string(bak1) != "v2" {
+		t.Errorf("bak.1 content = %q, want %q", bak1, "v2")
+	}
+
+	bak2, err := os.ReadFile(path + ".bak.2")
+	if err != nil {
+		t.Fatalf("ReadFile bak.2: %v", err)
+	}
+	if string(bak2) != "v1" {
+		t.Errorf("bak.2 content = %q, want %q", bak2, "v1")
+	}
+
+	if _, err := os.Stat(path + ".bak.3"); !os.IsNotExist(err) {
+		t.Error("SafeWrite should not keep more than KeepBackups backups")
+	}
+}
+
+func TestSafeWrite_FailingWriterLeavesOriginalIntact(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	if err := SafeWrite(path, []byte("original"), SafeWriteOptions{}); err != nil {
+		t.Fatalf("seeding original: %v", err)
+	}
+
+	original := writeTempFile
+	writeTempFile = func(f *os.File, data []byte) error {
+		f.Write(data[:len(data)/2])
+		return errors.New("simulated crash mid-write")
+	}
+	defer func() { writeTempFile = original }()
+
+	err := SafeWrite(path, []byte("corrupted-replacement"), SafeWriteOptions{})
+	if err == nil {
+		t.Fatal("SafeWrite() with a crashing writer should return an error")
+	}
+
+	content, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("ReadFile: %v", readErr)
+	}
+	if string(content) != "original" {
+		t.Errorf("a failed write should leave the original file untouched, got %q", content)
+	}
+
+	if _, err := os.Stat(path + ".bak.1"); !os.IsNotExist(err) {
+		t.Error("a failed write should not have rotated backups yet")
+	}
+}
+
+func TestRollback_RestoresMostRecentBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	if err := SafeWrite(path, []byte("good"), SafeWriteOptions{}); err != nil {
+		t.Fatalf("seeding good version: %v", err)
+	}
+	if err := SafeWrite(path, []byte("bad"), SafeWriteOptions{}); err != nil {
+		t.Fatalf("writing bad version: %v", err)
+	}
+
+	if err := Rollback(path); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(content) != "good" {
+		t.Errorf("content after Rollback = %q, want %q", content, "good")
+	}
+
+	// Rollback itself went through SafeWrite, so the bad version it
+	// replaced should now be recoverable too.
+	if err := Rollback(path); err != nil {
+		t.Fatalf("second Rollback() error = %v", err)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile after second rollback: %v", err)
+	}
+	if string(content) != "bad" {
+		t.Errorf("content after second Rollback = %q, want %q", content, "bad")
+	}
+}
+
+func TestRollback_NoBackupReturnsError(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "config.json")
+
+	if err := SafeWrite(path, []byte("only-version"), SafeWriteOptions{}); err != nil {
+		t.Fatalf("SafeWrite: %v", err)
+	}
+
+	if err := Rollback(path); err == nil {
+		t.Error("Rollback() with no prior backup should return an error")
+	}
+}
+
+func TestRollbackAgent(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := Config{WorkDir: tmpDir, RoleType: Autonomous}
+
+	gen := &MCPGenerator{}
+	if err := gen.Generate(cfg); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	configPath := filepath.Join(tmpDir, gen.Path())
+	good, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	// Simulate a bad write landing on top of the good config (e.g. a
+	// corrupted merge), rotating "good" into a backup.
+	if err := SafeWrite(configPath, []byte(`{"corrupted":true}`), SafeWriteOptions{}); err != nil {
+		t.Fatalf("simulating a bad write: %v", err)
+	}
+
+	if err := RollbackAgent(AgentMCP, cfg); err != nil {
+		t.Fatalf("RollbackAgent() error = %v", err)
+	}
+
+	restored, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("ReadFile after rollback: %v", err)
+	}
+	if string(restored) != string(good) {
+		t.Errorf("RollbackAgent should restore the pre-corruption config, got %q want %q", restored, good)
+	}
+}
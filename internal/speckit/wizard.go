@@ -0,0 +1,312 @@
+package speckit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrWizardAborted is returned by Wizard.Run when an interactive user
+// declines the final confirmation prompt.
+var ErrWizardAborted = errors.New("speckit: wizard aborted")
+
+// Roles lists the roles Wizard.Run offers, in prompt order.
+var Roles = []string{"mayor", "crew", "polecat", "witness", "refinery", "deacon"}
+
+// WizardIO bundles the wizard's input/output streams.
+type WizardIO struct {
+	In  io.Reader
+	Out io.Writer
+}
+
+// WizardOptions configures how Wizard.Run gathers its answers.
+type WizardOptions struct {
+	// NonInteractive skips every prompt, falling back to detected defaults
+	// (and whatever AnswersPath supplies) without asking for confirmation.
+	// Intended for CI and scripted onboarding.
+	NonInteractive bool
+	// AnswersPath, if set, pre-seeds the wizard's answers from a YAML-ish
+	// file (see ParseAnswersFile). Any field the file doesn't set still
+	// falls back to detection or an interactive prompt.
+	AnswersPath string
+}
+
+// Wizard walks a new user through picking a role, selecting which agents to
+// configure, and previewing the files EnsureConfig will write, for `gt
+// speckit init`.
+type Wizard struct {
+	Options WizardOptions
+
+	// scanner is shared by every prompt of one Run, so lines a prompt has
+	// already buffered from the input stay available to the next prompt.
+	scanner *bufio.Scanner
+}
+
+// NewWizard creates a Wizard with the given options.
+func NewWizard(opts WizardOptions) *Wizard {
+	return &Wizard{Options: opts}
+}
+
+// Run resolves a Config and the set of AgentTypes to configure for workDir,
+// previews the files that will be written, and (unless the caller backs
+// out of the confirmation prompt) calls EnsureConfig for each selected
+// AgentType. It returns ErrWizardAborted if an interactive user declines.
+func (w *Wizard) Run(ctx context.Context, wio WizardIO, workDir string) (Config, []AgentType, error) {
+	answers, err := w.loadAnswers()
+	if err != nil {
+		return Config{}, nil, err
+	}
+	w.scanner = bufio.NewScanner(wio.In)
+
+	role, err := w.resolveRole(wio, answers)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	agentTypes, err := w.resolveAgentTypes(wio, workDir, answers)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	gtPath := answers.GtPath
+	if gtPath == "" {
+		if resolved, err := exec.LookPath("gt"); err == nil {
+			gtPath = resolved
+		}
+	}
+
+	cfg := Config{
+		Role:     role,
+		RoleType: RoleTypeFor(role),
+		WorkDir:  workDir,
+		GtPath:   gtPath,
+	}
+
+	w.preview(wio, cfg, agentTypes)
+
+	if !w.Options.NonInteractive {
+		ok, err := w.confirm(wio)
+		if err != nil {
+			return cfg, agentTypes, err
+		}
+		if !ok {
+			return cfg, agentTypes, ErrWizardAborted
+		}
+	}
+
+	for _, at := range agentTypes {
+		if err := EnsureConfig(at, cfg); err != nil {
+			return cfg, agentTypes, fmt.Errorf("configuring %s: %w", at, err)
+		}
+	}
+
+	return cfg, agentTypes, nil
+}
+
+// wizardAnswers holds pre-seeded wizard answers, typically loaded from an
+// answers file via ParseAnswersFile for --non-interactive / --answers runs.
+type wizardAnswers struct {
+	Role   string
+	Agents []string
+	GtPath string
+}
+
+func (w *Wizard) loadAnswers() (*wizardAnswers, error) {
+	if w.Options.AnswersPath == "" {
+		return &wizardAnswers{}, nil
+	}
+	return ParseAnswersFile(w.Options.AnswersPath)
+}
+
+// ParseAnswersFile parses a small subset of YAML sufficient for wizard
+// answers files: flat "key: value" scalars, inline "key: [a, b]" lists, and
+// block lists ("key:" followed by indented "- item" lines). Gastown has no
+// other YAML usage to match, so this intentionally avoids pulling in a full
+// YAML parser for a handful of scalar/list fields.
+func ParseAnswersFile(path string) (*wizardAnswers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading answers file: %w", err)
+	}
+
+	answers := &wizardAnswers{}
+	var currentListKey string
+
+	for _, raw := range strings.Split(string(data), "\n") {
+		line := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "-") && currentListKey != "" && line != trimmed {
+			item := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")), `"'`)
+			if currentListKey == "agents" && item != "" {
+				answers.Agents = append(answers.Agents, item)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		if value == "" {
+			currentListKey = key
+			continue
+		}
+		currentListKey = ""
+
+		switch key {
+		case "role":
+			answers.Role = value
+		case "gt_path":
+			answers.GtPath = value
+		case "agents":
+			for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					answers.Agents = append(answers.Agents, item)
+				}
+			}
+		}
+	}
+
+	return answers, nil
+}
+
+func (w *Wizard) resolveRole(wio WizardIO, answers *wizardAnswers) (string, error) {
+	if answers.Role != "" {
+		return answers.Role, nil
+	}
+	if w.Options.NonInteractive {
+		return "crew", nil
+	}
+
+	fmt.Fprintln(wio.Out, "Which role is this for?")
+	for i, role := range Roles {
+		fmt.Fprintf(wio.Out, "  %d) %s\n", i+1, role)
+	}
+	fmt.Fprint(wio.Out, "> ")
+
+	scanner := w.scanner
+	if !scanner.Scan() {
+		return "", fmt.Errorf("reading role selection: %w", scanner.Err())
+	}
+	answer := strings.TrimSpace(scanner.Text())
+
+	for _, role := range Roles {
+		if strings.EqualFold(answer, role) {
+			return role, nil
+		}
+	}
+	if idx, err := strconv.Atoi(answer); err == nil && idx >= 1 && idx <= len(Roles) {
+		return Roles[idx-1], nil
+	}
+	return "", fmt.Errorf("unrecognized role %q", answer)
+}
+
+func (w *Wizard) resolveAgentTypes(wio WizardIO, workDir string, answers *wizardAnswers) ([]AgentType, error) {
+	if len(answers.Agents) > 0 {
+		return parseAgentTypes(answers.Agents)
+	}
+
+	detected := detectAgentTypes(workDir)
+	if w.Options.NonInteractive {
+		return detected, nil
+	}
+
+	fmt.Fprintf(wio.Out, "Detected agents: %s\n", joinAgentTypes(detected))
+	fmt.Fprint(wio.Out, "Configure these agents? [Y/n] ")
+
+	scanner := w.scanner
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("reading agent confirmation: %w", scanner.Err())
+	}
+	if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer == "" || answer == "y" || answer == "yes" {
+		return detected, nil
+	}
+
+	fmt.Fprint(wio.Out, "Enter comma-separated agent types to configure: ")
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("reading agent list: %w", scanner.Err())
+	}
+	return parseAgentTypes(strings.Split(scanner.Text(), ","))
+}
+
+// detectAgentTypes asks every registered generator whether it thinks it's
+// already in play in workDir (see Generator.Detect). It's a best-effort
+// default for the wizard and --non-interactive mode, not an exhaustive
+// check.
+func detectAgentTypes(workDir string) []AgentType {
+	var detected []AgentType
+	for _, at := range AllAgentTypes() {
+		gen, err := GetGenerator(at)
+		if err != nil {
+			continue
+		}
+		if gen.Detect(workDir) {
+			detected = append(detected, at)
+		}
+	}
+	return detected
+}
+
+func parseAgentTypes(names []string) ([]AgentType, error) {
+	var types []AgentType
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		at := AgentType(name)
+		if _, err := GetGenerator(at); err != nil {
+			return nil, err
+		}
+		types = append(types, at)
+	}
+	return types, nil
+}
+
+func joinAgentTypes(types []AgentType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (w *Wizard) preview(wio WizardIO, cfg Config, agentTypes []AgentType) {
+	fmt.Fprintf(wio.Out, "\nRole: %s (%s)\n", cfg.Role, cfg.RoleType)
+	fmt.Fprintf(wio.Out, "Work dir: %s\n", cfg.WorkDir)
+	if cfg.GtPath != "" {
+		fmt.Fprintf(wio.Out, "gt binary: %s\n", cfg.GtPath)
+	}
+	fmt.Fprintln(wio.Out, "Files that will be written:")
+	for _, at := range agentTypes {
+		gen, err := GetGenerator(at)
+		if err != nil || gen.Path() == "" {
+			continue
+		}
+		fmt.Fprintf(wio.Out, "  %s -> %s\n", at, filepath.Join(cfg.WorkDir, gen.Path()))
+	}
+}
+
+func (w *Wizard) confirm(wio WizardIO) (bool, error) {
+	fmt.Fprint(wio.Out, "\nProceed? [Y/n] ")
+	scanner := w.scanner
+	if !scanner.Scan() {
+		return false, fmt.Errorf("reading confirmation: %w", scanner.Err())
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "" || answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,137 @@
+package speckit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWizard_NonInteractiveWritesSelectedConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".claude"), 0755); err != nil {
+		t.Fatalf("mkdir .claude: %v", err)
+	}
+
+	w := NewWizard(WizardOptions{NonInteractive: true})
+	var out bytes.Buffer
+	cfg, agentTypes, err := w.Run(context.Background(), WizardIO{In: strings.NewReader(""), Out: &out}, tmpDir)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if cfg.Role != "crew" {
+		t.Errorf("Role = %q, want %q", cfg.Role, "crew")
+	}
+
+	found := false
+	for _, at := range agentTypes {
+		if at == AgentClaude {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("agentTypes = %v, want to include %q (detected via .claude/)", agentTypes, AgentClaude)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, ".claude", "settings.json")); err != nil {
+		t.Errorf("expected .claude/settings.json to be written: %v", err)
+	}
+}
+
+func TestWizard_InteractiveAbortReturnsErrWizardAborted(t *testing.T) {
+	tmpDir := t.TempDir()
+	w := NewWizard(WizardOptions{})
+
+	in := strings.NewReader("crew\nn\n\nn\n")
+	var out bytes.Buffer
+	_, _, err := w.Run(context.Background(), WizardIO{In: in, Out: &out}, tmpDir)
+	if err != ErrWizardAborted {
+		t.Fatalf("Run() error = %v, want ErrWizardAborted", err)
+	}
+}
+
+func TestParseAnswersFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	answersPath := filepath.Join(tmpDir, "answers.yaml")
+	content := `role: witness
+gt_path: /usr/local/bin/gt
+agents:
+  - claude
+  - cli
+`
+	if err := os.WriteFile(answersPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write answers file: %v", err)
+	}
+
+	answers, err := ParseAnswersFile(answersPath)
+	if err != nil {
+		t.Fatalf("ParseAnswersFile() error = %v", err)
+	}
+
+	if answers.Role != "witness" {
+		t.Errorf("Role = %q, want %q", answers.Role, "witness")
+	}
+	if answers.GtPath != "/usr/local/bin/gt" {
+		t.Errorf("GtPath = %q, want %q", answers.GtPath, "/usr/local/bin/gt")
+	}
+	if len(answers.Agents) != 2 || answers.Agents[0] != "claude" || answers.Agents[1] != "cli" {
+		t.Errorf("Agents = %v, want [claude cli]", answers.Agents)
+	}
+}
+
+func TestParseAnswersFile_InlineAgentsList(t *testing.T) {
+	tmpDir := t.TempDir()
+	answersPath := filepath.Join(tmpDir, "answers.yaml")
+	content := "role: mayor\nagents: [claude, cursor]\n"
+	if err := os.WriteFile(answersPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write answers file: %v", err)
+	}
+
+	answers, err := ParseAnswersFile(answersPath)
+	if err != nil {
+		t.Fatalf("ParseAnswersFile() error = %v", err)
+	}
+	if len(answers.Agents) != 2 || answers.Agents[0] != "claude" || answers.Agents[1] != "cursor" {
+		t.Errorf("Agents = %v, want [claude cursor]", answers.Agents)
+	}
+}
+
+func TestWizard_AnswersFileSkipsPrompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	answersPath := filepath.Join(tmpDir, "answers.yaml")
+	content := "role: polecat\nagents: [cli]\n"
+	if err := os.WriteFile(answersPath, []byte(content), 0644); err != nil {
+		t.Fatalf("write answers file: %v", err)
+	}
+
+	w := NewWizard(WizardOptions{NonInteractive: true, AnswersPath: answersPath})
+	var out bytes.Buffer
+	cfg, agentTypes, err := w.Run(context.Background(), WizardIO{In: strings.NewReader(""), Out: &out}, tmpDir)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if cfg.Role != "polecat" {
+		t.Errorf("Role = %q, want %q", cfg.Role, "polecat")
+	}
+	if len(agentTypes) != 1 || agentTypes[0] != AgentCLI {
+		t.Errorf("agentTypes = %v, want [%s]", agentTypes, AgentCLI)
+	}
+}
+
+func TestDetectAgentTypes_AlwaysIncludesCLI(t *testing.T) {
+	tmpDir := t.TempDir()
+	detected := detectAgentTypes(tmpDir)
+
+	found := false
+	for _, at := range detected {
+		if at == AgentCLI {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("detectAgentTypes() = %v, want to always include %q", detected, AgentCLI)
+	}
+}
@@ -0,0 +1,192 @@
+package witness
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+const witnessSessionPrefix = "gt-"
+const witnessSessionSuffix = "-witness"
+
+// WitnessInfo describes one discovered witness tmux session.
+type WitnessInfo struct {
+	RigName   string
+	Session   string
+	Running   bool
+	Attached  bool
+}
+
+// Fleet discovers and operates on every witness session across a town,
+// instead of requiring one Manager per rig.
+type Fleet struct {
+	townRoot string
+}
+
+// NewFleet returns a Fleet rooted at townRoot.
+func NewFleet(townRoot string) *Fleet {
+	return &Fleet{townRoot: townRoot}
+}
+
+// List returns every discovered witness session whose rig name contains
+// filter (a case-insensitive substring match; empty filter matches all).
+func (f *Fleet) List(filter string) ([]WitnessInfo, error) {
+	t := tmux.NewTmux()
+	sessions, err := t.ListSessions()
+	if err != nil {
+		return nil, fmt.Errorf("listing tmux sessions: %w", err)
+	}
+
+	filter = strings.ToLower(filter)
+	var infos []WitnessInfo
+	for _, name := range sessions {
+		rigName, ok := rigNameFromWitnessSession(name)
+		if !ok {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(rigName), filter) {
+			continue
+		}
+		info := WitnessInfo{RigName: rigName, Session: name, Running: true}
+		if attached, err := t.SessionAttached(name); err == nil {
+			info.Attached = attached
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// PrintList passes List's result to printf: one line per witness with a
+// "*" marker for an attached session, the padded rig name and the session
+// name, or bare session names when quiet is true (script-friendly).
+func PrintList(infos []WitnessInfo, quiet bool, printf func(format string, args ...any)) {
+	for _, info := range infos {
+		if quiet {
+			printf("%s\n", info.Session)
+			continue
+		}
+		marker := " "
+		if info.Attached {
+			marker = "*"
+		}
+		printf("%s %-20s %s\n", marker, info.RigName, info.Session)
+	}
+}
+
+// Attach attaches to the witness session of the given rig name through
+// tmux.AttachSession, returning its result. It returns an error without
+// attaching if no witness session is running for rigName.
+func (f *Fleet) Attach(rigName string) error {
+	session := witnessSessionName(rigName)
+	t := tmux.NewTmux()
+	running, err := t.HasSession(session)
+	if err != nil {
+		return fmt.Errorf("checking session %s: %w", session, err)
+	}
+	if !running {
+		return fmt.Errorf("no witness session running for rig %q", rigName)
+	}
+	return t.AttachSession(session)
+}
+
+// Switch attaches to the witness for rigName, remembering the witness we
+// were previously attached to (if any) so a later Switch("") can toggle
+// back. If rigName is empty, it switches to the previously remembered
+// witness instead (SwitchPrevious).
+func (f *Fleet) Switch(rigName string, detach bool) error {
+	if rigName == "" {
+		return f.SwitchPrevious()
+	}
+
+	current, err := f.loadState()
+	if err != nil {
+		return err
+	}
+	next := &fleetState{Current: rigName, Previous: current.previousOrEmpty()}
+	if current != nil && current.Current != "" && current.Current != rigName {
+		next.Previous = current.Current
+	}
+	if err := f.saveState(next); err != nil {
+		return err
+	}
+
+	if detach {
+		return nil
+	}
+	return f.Attach(rigName)
+}
+
+// SwitchPrevious attaches to the witness that was active before the current
+// one, toggling between the two most recently attached witnesses.
+func (f *Fleet) SwitchPrevious() error {
+	state, err := f.loadState()
+	if err != nil {
+		return err
+	}
+	if state == nil || state.Previous == "" {
+		return fmt.Errorf("no previous witness to switch to")
+	}
+	return f.Switch(state.Previous, false)
+}
+
+type fleetState struct {
+	Current  string `json:"current"`
+	Previous string `json:"previous"`
+}
+
+func (s *fleetState) previousOrEmpty() string {
+	if s == nil {
+		return ""
+	}
+	return s.Previous
+}
+
+func (f *Fleet) statePath() string {
+	return filepath.Join(f.townRoot, ".gastown", "witness-fleet-state.json")
+}
+
+func (f *Fleet) loadState() (*fleetState, error) {
+	data, err := os.ReadFile(f.statePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading witness fleet state: %w", err)
+	}
+	var state fleetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing witness fleet state: %w", err)
+	}
+	return &state, nil
+}
+
+func (f *Fleet) saveState(state *fleetState) error {
+	path := f.statePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding witness fleet state: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func witnessSessionName(rigName string) string {
+	return witnessSessionPrefix + rigName + witnessSessionSuffix
+}
+
+func rigNameFromWitnessSession(session string) (string, bool) {
+	if !strings.HasPrefix(session, witnessSessionPrefix) || !strings.HasSuffix(session, witnessSessionSuffix) {
+		return "", false
+	}
+	rigName := strings.TrimSuffix(strings.TrimPrefix(session, witnessSessionPrefix), witnessSessionSuffix)
+	if rigName == "" {
+		return "", false
+	}
+	return rigName, true
+}
@@ -0,0 +1,55 @@
+package witness
+
+import "testing"
+
+func TestRigNameFromWitnessSession(t *testing.T) {
+	tests := []struct {
+		session string
+		want    string
+		ok      bool
+	}{
+		{"gt-myrig-witness", "myrig", true},
+		{"gt--witness", "", false},
+		{"gt-myrig-deacon", "", false},
+		{"myrig-witness", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := rigNameFromWitnessSession(tt.session)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("rigNameFromWitnessSession(%q) = (%q, %v), want (%q, %v)", tt.session, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWitnessSessionName(t *testing.T) {
+	if got := witnessSessionName("myrig"); got != "gt-myrig-witness" {
+		t.Errorf("witnessSessionName(myrig) = %q, want gt-myrig-witness", got)
+	}
+}
+
+func TestFleetState_SaveAndLoadRoundTrip(t *testing.T) {
+	f := NewFleet(t.TempDir())
+
+	if state, err := f.loadState(); err != nil || state != nil {
+		t.Fatalf("expected no state initially, got %v, %v", state, err)
+	}
+
+	if err := f.saveState(&fleetState{Current: "rigA", Previous: "rigB"}); err != nil {
+		t.Fatalf("saveState: %v", err)
+	}
+
+	state, err := f.loadState()
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if state.Current != "rigA" || state.Previous != "rigB" {
+		t.Fatalf("loadState = %+v, want {rigA rigB}", state)
+	}
+}
+
+func TestFleetSwitchPrevious_NoHistoryErrors(t *testing.T) {
+	f := NewFleet(t.TempDir())
+	if err := f.SwitchPrevious(); err == nil {
+		t.Fatal("expected error when there is no previous witness")
+	}
+}
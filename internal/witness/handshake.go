@@ -0,0 +1,127 @@
+package witness
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Capabilities is what an agent reports back during the startup handshake,
+// replacing the previous timing-heuristic approach (fixed sleeps, prompt
+// prefix sniffing) with an explicit, testable negotiation.
+type Capabilities struct {
+	Token             string `json:"token"`
+	ProtocolVersion    int    `json:"protocol_version"`
+	SupportsResume     bool   `json:"supports_resume"`
+	SupportsPropulsion bool   `json:"supports_propulsion"`
+}
+
+// Handshake drives the startup handshake for one session over a pair of
+// control files under controlDir (normally $XDG_RUNTIME_DIR/gastown, falling
+// back to os.TempDir()/gastown). Using plain files rather than a unix socket keeps
+// the protocol testable without tmux or a running agent: a test can write
+// the ack file directly and assert on Await's result.
+type Handshake struct {
+	controlDir string
+	sessionID  string
+}
+
+// NewHandshake returns a Handshake for sessionID, rooted at controlDir.
+// If controlDir is empty, it resolves $XDG_RUNTIME_DIR/gastown, falling back
+// to os.TempDir()/gastown.
+func NewHandshake(controlDir, sessionID string) *Handshake {
+	if controlDir == "" {
+		controlDir = defaultControlDir()
+	}
+	return &Handshake{controlDir: controlDir, sessionID: sessionID}
+}
+
+func defaultControlDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gastown")
+	}
+	return filepath.Join(os.TempDir(), "gastown")
+}
+
+// NewToken generates a random handshake token for the agent's SessionStart
+// hook to echo back.
+func NewToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating handshake token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (h *Handshake) tokenPath() string {
+	return filepath.Join(h.controlDir, h.sessionID+".token")
+}
+
+func (h *Handshake) ackPath() string {
+	return filepath.Join(h.controlDir, h.sessionID+".ack")
+}
+
+// WriteToken publishes token to the control file the agent's SessionStart
+// hook reads, so it can echo it back in the ack.
+func (h *Handshake) WriteToken(token string) error {
+	if err := os.MkdirAll(h.controlDir, 0700); err != nil {
+		return fmt.Errorf("creating control dir: %w", err)
+	}
+	return os.WriteFile(h.tokenPath(), []byte(token), 0600)
+}
+
+// Ack is called by the agent side (SessionStart hook, or a test standing in
+// for it) to report its capabilities back to the waiting manager.
+func (h *Handshake) Ack(caps Capabilities) error {
+	if err := os.MkdirAll(h.controlDir, 0700); err != nil {
+		return fmt.Errorf("creating control dir: %w", err)
+	}
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return fmt.Errorf("encoding ack: %w", err)
+	}
+	return os.WriteFile(h.ackPath(), data, 0600)
+}
+
+// Await polls for an ack matching token until timeout elapses, returning the
+// reported Capabilities. A mismatched or missing token is treated as "no ack
+// yet" rather than an error, since a stale ack file from a previous session
+// must not be mistaken for this one's.
+func (h *Handshake) Await(token string, timeout time.Duration) (*Capabilities, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if caps, ok := h.readAck(token); ok {
+			return caps, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for handshake ack from session %s", h.sessionID)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (h *Handshake) readAck(token string) (*Capabilities, bool) {
+	data, err := os.ReadFile(h.ackPath())
+	if err != nil {
+		return nil, false
+	}
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return nil, false
+	}
+	if caps.Token != token {
+		return nil, false
+	}
+	return &caps, true
+}
+
+// Cleanup removes the handshake's control files. Safe to call even if they
+// were never created.
+func (h *Handshake) Cleanup() {
+	_ = os.Remove(h.tokenPath())
+	_ = os.Remove(h.ackPath())
+}
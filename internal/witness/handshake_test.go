@@ -0,0 +1,134 @@
+package witness
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandshake_AwaitMatchesTokenAck(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHandshake(dir, "gt-myrig-witness")
+
+	token, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	if err := h.WriteToken(token); err != nil {
+		t.Fatalf("WriteToken: %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = h.Ack(Capabilities{
+			Token:              token,
+			ProtocolVersion:    1,
+			SupportsResume:     true,
+			SupportsPropulsion: true,
+		})
+	}()
+
+	caps, err := h.Await(token, time.Second)
+	if err != nil {
+		t.Fatalf("Await: %v", err)
+	}
+	if !caps.SupportsPropulsion || !caps.SupportsResume || caps.ProtocolVersion != 1 {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+}
+
+func TestHandshake_Await_IgnoresStaleTokenMismatch(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHandshake(dir, "gt-myrig-witness")
+
+	if err := h.Ack(Capabilities{Token: "stale-token"}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	_, err := h.Await("current-token", 150*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected timeout when the only ack on disk has a stale token")
+	}
+}
+
+func TestHandshake_Await_TimesOutWithNoAck(t *testing.T) {
+	h := NewHandshake(t.TempDir(), "gt-myrig-witness")
+	if _, err := h.Await("tok", 150*time.Millisecond); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+func TestDecideReadyWait_FastAckSkipsLegacyHeuristic(t *testing.T) {
+	h := NewHandshake(t.TempDir(), "gt-myrig-witness")
+	token, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = h.Ack(Capabilities{Token: token, SupportsPropulsion: true})
+	}()
+
+	start := time.Now()
+	acked, sendNudge := decideReadyWait(h, token, handshakeAckTimeout)
+	elapsed := time.Since(start)
+
+	if !acked {
+		t.Fatal("expected decideReadyWait to report an ack")
+	}
+	if !sendNudge {
+		t.Error("expected sendNudge to reflect the acked capability")
+	}
+	if elapsed >= handshakeAckTimeout {
+		t.Fatalf("decideReadyWait took %v, expected it to return as soon as the ack arrived well under the %v timeout", elapsed, handshakeAckTimeout)
+	}
+}
+
+func TestDecideReadyWait_NoAckFallsBackToLegacyHeuristic(t *testing.T) {
+	h := NewHandshake(t.TempDir(), "gt-myrig-witness")
+	token, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+
+	acked, sendNudge := decideReadyWait(h, token, 50*time.Millisecond)
+	if acked {
+		t.Fatal("expected decideReadyWait to report no ack when none arrives")
+	}
+	if !sendNudge {
+		t.Error("expected sendNudge to default to true for the legacy fallback path")
+	}
+}
+
+func TestDecideReadyWait_EmptyTokenFallsBackImmediately(t *testing.T) {
+	h := NewHandshake(t.TempDir(), "gt-myrig-witness")
+
+	start := time.Now()
+	acked, sendNudge := decideReadyWait(h, "", time.Second)
+	elapsed := time.Since(start)
+
+	if acked {
+		t.Fatal("expected an empty token to never be treated as acked")
+	}
+	if !sendNudge {
+		t.Error("expected sendNudge to default to true when there's no token to await")
+	}
+	if elapsed >= 100*time.Millisecond {
+		t.Fatalf("decideReadyWait took %v for an empty token, expected an immediate return", elapsed)
+	}
+}
+
+func TestHandshake_Cleanup(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHandshake(dir, "gt-myrig-witness")
+	if err := h.WriteToken("tok"); err != nil {
+		t.Fatalf("WriteToken: %v", err)
+	}
+	if err := h.Ack(Capabilities{Token: "tok"}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	h.Cleanup()
+	if _, ok := h.readAck("tok"); ok {
+		t.Fatal("expected ack file to be removed after Cleanup")
+	}
+}
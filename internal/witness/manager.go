@@ -35,6 +35,14 @@ var (
 // ZFC-compliant: tmux session is the source of truth for running state.
 type Manager struct {
 	rig *rig.Rig
+
+	// RuntimeOverride selects an AgentRuntime backend by name (see
+	// runtime.Register), e.g. from a --runtime flag. When it is empty, Start
+	// uses its agentOverride argument as the backend name instead (an
+	// agentOverride that names no registered backend makes Start fail), and
+	// only when both are empty is the backend inferred from the resolved
+	// agent config.
+	RuntimeOverride string
 }
 
 // NewManager creates a new witness manager for a rig.
@@ -89,6 +97,51 @@ func (m *Manager) witnessDir() string {
 	return m.rig.Path
 }
 
+// WorkDir returns the witness's effective working directory: witness/rig/
+// if present, else witness/, else the rig root. Exported so callers (e.g.
+// `gt witness path`, shell aliases) don't have to reimplement the fallback.
+func (m *Manager) WorkDir() string {
+	return m.witnessDir()
+}
+
+// AttachCommand returns the argv to exec to attach to this witness's tmux
+// session, e.g. ["tmux", "attach", "-t", "gt-myrig-witness"].
+func (m *Manager) AttachCommand() []string {
+	return []string{"tmux", "attach", "-t", m.SessionName()}
+}
+
+// witnessDirOverrideEnv lets a test (or a user who wants `gt witness path`
+// to point somewhere other than the real rig layout) short-circuit
+// PrintablePath. WorkDir itself does not read it.
+const witnessDirOverrideEnv = "GT_WITNESS_DIR_OVERRIDE"
+
+// handshakeAckTimeout bounds how long Start waits for the agent's
+// SessionStart hook to echo the handshake token back before giving up and
+// falling back to the pre-handshake timing heuristic. An agent that
+// implements the handshake should ack within moments of the hook firing,
+// so this is much shorter than the 30s+ the legacy heuristic allows.
+const handshakeAckTimeout = 5 * time.Second
+
+// PrintablePath returns the witness's working directory resolved to an
+// absolute, symlink-free path suitable for `cd $(gt witness path)`. It
+// honors GT_WITNESS_DIR_OVERRIDE so a user (or a test) can point it
+// elsewhere without touching the rig layout.
+func (m *Manager) PrintablePath() (string, error) {
+	dir := m.WorkDir()
+	if override := os.Getenv(witnessDirOverrideEnv); override != "" {
+		dir = override
+	}
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return filepath.Abs(dir)
+		}
+		return "", fmt.Errorf("resolving witness path: %w", err)
+	}
+	return resolved, nil
+}
+
 // Start starts the witness.
 // If foreground is true, returns an error (foreground mode deprecated).
 // Otherwise, spawns a Claude agent in a tmux session.
@@ -128,7 +181,15 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	witnessParentDir := filepath.Join(m.rig.Path, "witness")
 	townRoot := m.townRoot()
 	rc := config.ResolveRoleAgentConfig("witness", townRoot, m.rig.Path)
-	if err := runtime.EnsureSettingsForRole(witnessParentDir, "witness", rc); err != nil {
+	runtimeName := m.RuntimeOverride
+	if runtimeName == "" {
+		runtimeName = agentOverride
+	}
+	agentRuntime, err := runtime.ResolveRuntime(runtimeName, rc)
+	if err != nil {
+		return fmt.Errorf("resolving runtime backend: %w", err)
+	}
+	if err := agentRuntime.EnsureSettings(witnessParentDir, "witness", rc); err != nil {
 		return fmt.Errorf("ensuring runtime settings: %w", err)
 	}
 
@@ -136,27 +197,23 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	if err != nil {
 		return err
 	}
+	if agentOverride != "" {
+		// An explicit override means "run with this agent regardless of what
+		// the role bead says" - same precedence buildWitnessStartCommand used
+		// before agentRuntime existed.
+		roleConfig = nil
+	}
 
 	// Build startup command first
 	// NOTE: No gt prime injection needed - SessionStart hook handles it automatically
 	// Export GT_ROLE and BD_ACTOR in the command since tmux SetEnvironment only affects new panes
 	// Pass m.rig.Path so rig agent settings are honored (not town-level defaults)
-	command, err := buildWitnessStartCommand(m.rig.Path, m.rig.Name, townRoot, agentOverride, roleConfig)
+	command, err := buildWitnessStartCommand(agentRuntime, m.rig.Path, m.rig.Name, townRoot, roleConfig)
 	if err != nil {
 		return err
 	}
-	if agentOverride == "" {
-		agentOverride = defaultAgentOverride(command)
-	}
 
-	if err := config.EnsureCopilotTrustedFolder(config.CopilotTrustConfig{
-		Role:          "witness",
-		TownRoot:      townRoot,
-		RigPath:       m.rig.Path,
-		WorkDir:       witnessDir,
-		AgentOverride: agentOverride,
-		ConfigDir:     os.Getenv(runtimeConfigDirEnv(rc)),
-	}); err != nil {
+	if err := agentRuntime.TrustFolder("witness", townRoot, m.rig.Path, witnessDir, os.Getenv(runtimeConfigDirEnv(rc))); err != nil {
 		return err
 	}
 
@@ -166,6 +223,16 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		return fmt.Errorf("creating tmux session: %w", err)
 	}
 
+	// Publish a handshake token the agent's SessionStart hook can echo back
+	// (with its capabilities) before we send the propulsion nudge. Generating
+	// and writing the token is best-effort: an agent that never acks it just
+	// falls back to the existing timing-heuristic behavior below.
+	handshake := NewHandshake("", sessionID)
+	handshakeToken, _ := NewToken()
+	if handshakeToken != "" {
+		_ = handshake.WriteToken(handshakeToken)
+	}
+
 	// Set environment variables (non-fatal: session works without these)
 	// Use centralized AgentEnv for consistency across all role startup paths
 	sessionIDEnv := ""
@@ -181,6 +248,9 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	for k, v := range envVars {
 		_ = t.SetEnvironment(sessionID, k, v)
 	}
+	if handshakeToken != "" {
+		_ = t.SetEnvironment(sessionID, "GT_HANDSHAKE_TOKEN", handshakeToken)
+	}
 	// Apply role config env vars if present (non-fatal).
 	for key, value := range roleConfigEnvVars(roleConfig, townRoot, m.rig.Name) {
 		_ = t.SetEnvironment(sessionID, key, value)
@@ -204,10 +274,22 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	agentRuntime.AcceptWarnings(t, sessionID)
+
+	// GUPP: Gas Town Universal Propulsion Principle
+	// Try the handshake ack before falling into the legacy timing heuristic
+	// (fixed sleeps plus prompt-prefix polling) below: an agent whose
+	// SessionStart hook echoes the token back inside handshakeAckTimeout
+	// tells us definitively that it's ready and whether it wants a nudge at
+	// all, so none of the fixed delays below have anything left to wait
+	// for. Only an agent that never acks (no SessionStart hook support
+	// today, the common case) pays for that heuristic.
+	acked, sendNudge := decideReadyWait(handshake, handshakeToken, handshakeAckTimeout)
 
-	time.Sleep(constants.ShutdownNotifyDelay)
-	runtime.WaitForCopilotReady(t, sessionID, rc, 30*time.Second)
+	if !acked {
+		time.Sleep(constants.ShutdownNotifyDelay)
+		agentRuntime.WaitReady(t, sessionID, rc, 30*time.Second)
+	}
 
 	// Inject startup nudge for predecessor discovery via /resume
 	address := fmt.Sprintf("%s/witness", m.rig.Name)
@@ -217,14 +299,47 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		Topic:     "patrol",
 	}) // Non-fatal
 
-	runtime.SleepForReadyDelay(rc)
-	_ = runtime.RunStartupFallback(t, sessionID, "witness", rc)
+	if !acked {
+		runtime.SleepForReadyDelay(rc)
+		_ = runtime.RunStartupFallback(t, sessionID, "witness", rc)
+		waitForLegacyReadyHeuristic(t, sessionID, rc)
+	}
 
-	// Wait for runtime to be ready (prompt visible) before sending propulsion nudge.
-	// This prevents the Escape key in NudgeSession from canceling "Thinking" state.
-	// The startup nudge above triggers /resume beacon processing which puts Claude in
-	// "Thinking" state. We must wait for that to complete before sending the propulsion nudge.
-	// See: https://github.com/steveyegge/gastown/issues/hq-cv-5ktuq
+	if sendNudge {
+		_ = t.NudgeSession(sessionID, agentRuntime.PropulsionNudge("witness", witnessDir)) // Non-fatal
+	}
+	handshake.Cleanup()
+
+	return nil
+}
+
+// decideReadyWait waits up to timeout for the agent's SessionStart hook to
+// ack h's handshake token, reporting whether it acked in time and, if so,
+// whether it wants the propulsion nudge at all. A missing token (handshake
+// token generation failed) or a timed-out Await means the agent doesn't
+// implement the handshake, so the caller falls back to its legacy timing
+// heuristic and defaults to sending the nudge, matching pre-handshake
+// behavior. Split out from Start so the ack-gating decision is testable
+// without a tmux session: Handshake is file-based (see handshake.go).
+func decideReadyWait(h *Handshake, token string, timeout time.Duration) (acked, sendNudge bool) {
+	if token == "" {
+		return false, true
+	}
+	caps, err := h.Await(token, timeout)
+	if err != nil {
+		return false, true
+	}
+	return true, caps.SupportsPropulsion
+}
+
+// waitForLegacyReadyHeuristic waits for the runtime's prompt to reappear
+// after the startup nudge above triggers /resume beacon processing, falling
+// back to a fixed delay (10s minimum) if prompt detection fails. It exists
+// purely for agents that don't implement the handshake: an ack from
+// handshakeAckTimeout above replaces this wait entirely, so this only runs
+// for agents whose SessionStart hook never echoes the token back.
+// See: https://github.com/steveyegge/gastown/issues/hq-cv-5ktuq
+func waitForLegacyReadyHeuristic(t *tmux.Tmux, sessionID string, rc *config.RuntimeConfig) {
 	if err := t.WaitForRuntimeReady(sessionID, rc, 30*time.Second); err != nil {
 		// Non-fatal: if prompt detection fails, use longer fixed delay (10s minimum)
 		// to ensure beacon processing completes before propulsion nudge.
@@ -237,12 +352,6 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		}
 		time.Sleep(time.Duration(delay) * time.Millisecond)
 	}
-
-	// GUPP: Gas Town Universal Propulsion Principle
-	// Send the propulsion nudge to trigger autonomous patrol execution.
-	_ = t.NudgeSession(sessionID, session.PropulsionNudgeForRole("witness", witnessDir)) // Non-fatal
-
-	return nil
 }
 
 func (m *Manager) roleConfig() (*beads.RoleConfig, error) {
@@ -275,62 +384,24 @@ func roleConfigEnvVars(roleConfig *beads.RoleConfig, townRoot, rigName string) m
 	return expanded
 }
 
-func buildWitnessStartCommand(rigPath, rigName, townRoot, agentOverride string, roleConfig *beads.RoleConfig) (string, error) {
-	if agentOverride != "" {
-		roleConfig = nil
-	}
+// buildWitnessStartCommand returns roleConfig's own StartCommand if set,
+// otherwise asks agentRuntime to build the backend-appropriate startup
+// command (Claude, Copilot, shell, ...) instead of hardcoding one backend's
+// command-line shape.
+func buildWitnessStartCommand(agentRuntime runtime.AgentRuntime, rigPath, rigName, townRoot string, roleConfig *beads.RoleConfig) (string, error) {
 	if roleConfig != nil && roleConfig.StartCommand != "" {
 		return beads.ExpandRolePattern(roleConfig.StartCommand, townRoot, rigName, "", "witness"), nil
 	}
 	// Add initial prompt for autonomous patrol startup.
 	// The prompt triggers GUPP: witness starts patrol immediately without waiting for input.
 	initialPrompt := "I am Witness for " + rigName + ". Start patrol: check gt hook, if empty create mol-witness-patrol wisp and execute it."
-	command, err := config.BuildAgentStartupCommandWithAgentOverride("witness", rigName, townRoot, rigPath, initialPrompt, agentOverride)
+	command, err := agentRuntime.BuildStartCommand("witness", rigName, townRoot, rigPath, initialPrompt)
 	if err != nil {
 		return "", fmt.Errorf("building startup command: %w", err)
 	}
 	return command, nil
 }
 
-func defaultAgentOverride(command string) string {
-	if command == "" {
-		return ""
-	}
-	trimmed := strings.TrimSpace(command)
-	for strings.HasPrefix(trimmed, "export ") {
-		idx := strings.Index(trimmed, "&&")
-		if idx == -1 {
-			break
-		}
-		trimmed = strings.TrimSpace(trimmed[idx+2:])
-	}
-	fields := strings.Fields(trimmed)
-	if len(fields) == 0 {
-		return ""
-	}
-	if fields[0] == "exec" {
-		fields = fields[1:]
-	}
-	if len(fields) > 0 && fields[0] == "env" {
-		fields = fields[1:]
-		for len(fields) > 0 {
-			if fields[0] == "--" {
-				fields = fields[1:]
-				break
-			}
-			if strings.HasPrefix(fields[0], "-") || strings.Contains(fields[0], "=") {
-				fields = fields[1:]
-				continue
-			}
-			break
-		}
-	}
-	if len(fields) == 0 {
-		return ""
-	}
-	return filepath.Base(fields[0])
-}
-
 // Stop stops the witness.
 // ZFC-compliant: tmux session is the source of truth.
 func (m *Manager) Stop() error {
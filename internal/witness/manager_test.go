@@ -0,0 +1,64 @@
+package witness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestManager_WorkDirFallback(t *testing.T) {
+	tmp := t.TempDir()
+	m := NewManager(&rig.Rig{Name: "myrig", Path: tmp})
+
+	if got := m.WorkDir(); got != tmp {
+		t.Errorf("WorkDir() with no witness subdir = %q, want %q", got, tmp)
+	}
+
+	witnessDir := filepath.Join(tmp, "witness")
+	if err := os.MkdirAll(witnessDir, 0755); err != nil {
+		t.Fatalf("mkdir witness dir: %v", err)
+	}
+	if got := m.WorkDir(); got != witnessDir {
+		t.Errorf("WorkDir() with witness/ present = %q, want %q", got, witnessDir)
+	}
+
+	witnessRigDir := filepath.Join(tmp, "witness", "rig")
+	if err := os.MkdirAll(witnessRigDir, 0755); err != nil {
+		t.Fatalf("mkdir witness/rig dir: %v", err)
+	}
+	if got := m.WorkDir(); got != witnessRigDir {
+		t.Errorf("WorkDir() with witness/rig/ present = %q, want %q", got, witnessRigDir)
+	}
+}
+
+func TestManager_AttachCommand(t *testing.T) {
+	m := NewManager(&rig.Rig{Name: "myrig"})
+	want := []string{"tmux", "attach", "-t", "gt-myrig-witness"}
+	got := m.AttachCommand()
+	if len(got) != len(want) {
+		t.Fatalf("AttachCommand() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("AttachCommand()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestManager_PrintablePath_HonorsOverride(t *testing.T) {
+	tmp := t.TempDir()
+	m := NewManager(&rig.Rig{Name: "myrig", Path: filepath.Join(tmp, "does-not-exist")})
+
+	override := t.TempDir()
+	t.Setenv(witnessDirOverrideEnv, override)
+
+	got, err := m.PrintablePath()
+	if err != nil {
+		t.Fatalf("PrintablePath: %v", err)
+	}
+	if got != override {
+		t.Errorf("PrintablePath() = %q, want %q", got, override)
+	}
+}